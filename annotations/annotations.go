@@ -0,0 +1,234 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package annotations provides typed access to the pre-defined annotation
+// keys described in annotations.md.
+package annotations
+
+import (
+	_ "crypto/sha256" // side-effect to install impls, sha256
+	_ "crypto/sha512" // side-effect to install impls, sha384/sh512
+
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/spdx"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ErrNotSet is returned by the accessors of Map when the annotation is absent.
+var ErrNotSet = errors.New("annotation not set")
+
+// Map is a typed view over an annotations map, such as the Annotations field
+// of a v1.Manifest, v1.Index or v1.Descriptor. Reads never modify the
+// underlying map, writes allocate it if it is nil.
+type Map struct {
+	m *map[string]string
+}
+
+// Of returns a typed view over the annotations map pointed to by m, which
+// must not be nil.
+func Of(m *map[string]string) Map {
+	return Map{m: m}
+}
+
+func (a Map) get(key string) (string, error) {
+	v, ok := (*a.m)[key]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", key, ErrNotSet)
+	}
+	return v, nil
+}
+
+func (a Map) set(key, value string) {
+	if *a.m == nil {
+		*a.m = map[string]string{}
+	}
+	(*a.m)[key] = value
+}
+
+// Created returns the value of AnnotationCreated, parsed as an RFC 3339 date-time.
+func (a Map) Created() (time.Time, error) {
+	v, err := a.get(v1.AnnotationCreated)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: invalid RFC 3339 date-time %q: %w", v1.AnnotationCreated, v, err)
+	}
+	return t, nil
+}
+
+// SetCreated sets AnnotationCreated to t, formatted as an RFC 3339 date-time.
+func (a Map) SetCreated(t time.Time) {
+	a.set(v1.AnnotationCreated, t.Format(time.RFC3339Nano))
+}
+
+// Authors returns the comma separated entries of AnnotationAuthors.
+func (a Map) Authors() ([]string, error) {
+	v, err := a.get(v1.AnnotationAuthors)
+	if err != nil {
+		return nil, err
+	}
+	var authors []string
+	for _, author := range strings.Split(v, ",") {
+		if author = strings.TrimSpace(author); author != "" {
+			authors = append(authors, author)
+		}
+	}
+	return authors, nil
+}
+
+// SetAuthors sets AnnotationAuthors to the comma separated list of authors.
+func (a Map) SetAuthors(authors []string) {
+	a.set(v1.AnnotationAuthors, strings.Join(authors, ", "))
+}
+
+// URL returns the value of AnnotationURL.
+func (a Map) URL() (*url.URL, error) {
+	return a.url(v1.AnnotationURL)
+}
+
+// SetURL sets AnnotationURL to u.
+func (a Map) SetURL(u *url.URL) {
+	a.set(v1.AnnotationURL, u.String())
+}
+
+// Documentation returns the value of AnnotationDocumentation.
+func (a Map) Documentation() (*url.URL, error) {
+	return a.url(v1.AnnotationDocumentation)
+}
+
+// SetDocumentation sets AnnotationDocumentation to u.
+func (a Map) SetDocumentation(u *url.URL) {
+	a.set(v1.AnnotationDocumentation, u.String())
+}
+
+// Source returns the value of AnnotationSource.
+func (a Map) Source() (*url.URL, error) {
+	return a.url(v1.AnnotationSource)
+}
+
+// SetSource sets AnnotationSource to u.
+func (a Map) SetSource(u *url.URL) {
+	a.set(v1.AnnotationSource, u.String())
+}
+
+func (a Map) url(key string) (*url.URL, error) {
+	v, err := a.get(key)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	if !u.IsAbs() {
+		return nil, fmt.Errorf("%s: URL %q is not absolute", key, v)
+	}
+	return u, nil
+}
+
+// BaseImageDigest returns the value of AnnotationBaseImageDigest.
+// Digests using an algorithm not available to go-digest are returned without
+// error as long as they are well formed.
+func (a Map) BaseImageDigest() (digest.Digest, error) {
+	v, err := a.get(v1.AnnotationBaseImageDigest)
+	if err != nil {
+		return "", err
+	}
+	d := digest.Digest(v)
+	if err := d.Validate(); err != nil && !errors.Is(err, digest.ErrDigestUnsupported) {
+		return "", fmt.Errorf("%s: %w", v1.AnnotationBaseImageDigest, err)
+	}
+	return d, nil
+}
+
+// SetBaseImageDigest sets AnnotationBaseImageDigest to d.
+func (a Map) SetBaseImageDigest(d digest.Digest) {
+	a.set(v1.AnnotationBaseImageDigest, d.String())
+}
+
+// Licenses returns the value of AnnotationLicenses, parsed as an SPDX
+// license expression.
+func (a Map) Licenses() (spdx.Expression, error) {
+	v, err := a.get(v1.AnnotationLicenses)
+	if err != nil {
+		return nil, err
+	}
+	e, err := spdx.Parse(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", v1.AnnotationLicenses, err)
+	}
+	return e, nil
+}
+
+// SetLicenses sets AnnotationLicenses to the canonical form of e.
+func (a Map) SetLicenses(e spdx.Expression) {
+	a.set(v1.AnnotationLicenses, e.String())
+}
+
+// Validate checks that every pre-defined annotation with a typed accessor
+// holds a well formed value. Absent annotations are not an error.
+func (a Map) Validate() error {
+	for _, check := range []func() error{
+		func() error { _, err := a.Created(); return err },
+		func() error { _, err := a.URL(); return err },
+		func() error { _, err := a.Documentation(); return err },
+		func() error { _, err := a.Source(); return err },
+		func() error { _, err := a.BaseImageDigest(); return err },
+		func() error { _, err := a.Licenses(); return err },
+	} {
+		if err := check(); err != nil && !errors.Is(err, ErrNotSet) {
+			return err
+		}
+	}
+	return nil
+}
+
+// LicenseIDs returns the sorted set of license identifiers declared by the
+// AnnotationLicenses key of each of the given maps, typically the annotations
+// of an index and manifest and the labels of an image configuration. An error
+// is returned if any expression is malformed or uses an identifier that is
+// not on the SPDX License List.
+func LicenseIDs(maps ...map[string]string) ([]string, error) {
+	set := map[string]struct{}{}
+	for i := range maps {
+		e, err := Of(&maps[i]).Licenses()
+		if errors.Is(err, ErrNotSet) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := spdx.Validate(e); err != nil {
+			return nil, fmt.Errorf("%s: %w", v1.AnnotationLicenses, err)
+		}
+		for _, id := range spdx.LicenseIDs(e) {
+			set[id] = struct{}{}
+		}
+	}
+	ids := make([]string, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
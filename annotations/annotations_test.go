@@ -0,0 +1,134 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/spdx"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestRoundTrip(t *testing.T) {
+	var desc v1.Descriptor
+	a := Of(&desc.Annotations)
+
+	if _, err := a.Created(); !errors.Is(err, ErrNotSet) {
+		t.Fatalf("expected ErrNotSet, got %v", err)
+	}
+
+	created := time.Date(2015, 10, 31, 22, 22, 56, 15925234, time.UTC)
+	a.SetCreated(created)
+	a.SetAuthors([]string{"Alyssa P. Hacker <alyspdev@example.com>", "Ben Bitdiddle"})
+	u, _ := url.Parse("https://example.com/project")
+	a.SetURL(u)
+	a.SetSource(u)
+	a.SetDocumentation(u)
+	d := digest.FromString("base")
+	a.SetBaseImageDigest(d)
+	licenses, err := spdx.Parse("MIT OR Apache-2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.SetLicenses(licenses)
+
+	expected := map[string]string{
+		v1.AnnotationCreated:         "2015-10-31T22:22:56.015925234Z",
+		v1.AnnotationAuthors:         "Alyssa P. Hacker <alyspdev@example.com>, Ben Bitdiddle",
+		v1.AnnotationURL:             "https://example.com/project",
+		v1.AnnotationSource:          "https://example.com/project",
+		v1.AnnotationDocumentation:   "https://example.com/project",
+		v1.AnnotationBaseImageDigest: d.String(),
+		v1.AnnotationLicenses:        "MIT OR Apache-2.0",
+	}
+	if !reflect.DeepEqual(desc.Annotations, expected) {
+		t.Fatalf("unexpected annotations: %v", desc.Annotations)
+	}
+	if err := a.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := a.Created(); err != nil || !got.Equal(created) {
+		t.Errorf("unexpected created: %v, %v", got, err)
+	}
+	if got, err := a.Authors(); err != nil || len(got) != 2 || got[1] != "Ben Bitdiddle" {
+		t.Errorf("unexpected authors: %v, %v", got, err)
+	}
+	if got, err := a.BaseImageDigest(); err != nil || got != d {
+		t.Errorf("unexpected base image digest: %v, %v", got, err)
+	}
+	if got, err := a.Licenses(); err != nil || got.String() != "MIT OR Apache-2.0" {
+		t.Errorf("unexpected licenses: %v, %v", got, err)
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	for _, tt := range []struct {
+		key   string
+		value string
+		get   func(Map) error
+	}{
+		{v1.AnnotationCreated, "2015-10-31 22:22:56", func(a Map) error { _, err := a.Created(); return err }},
+		{v1.AnnotationURL, "example.com", func(a Map) error { _, err := a.URL(); return err }},
+		{v1.AnnotationSource, "://", func(a Map) error { _, err := a.Source(); return err }},
+		{v1.AnnotationDocumentation, "/docs", func(a Map) error { _, err := a.Documentation(); return err }},
+		{v1.AnnotationBaseImageDigest, "sha256:xyz", func(a Map) error { _, err := a.BaseImageDigest(); return err }},
+		{v1.AnnotationLicenses, "Apache 2", func(a Map) error { _, err := a.Licenses(); return err }},
+	} {
+		t.Run(tt.key, func(t *testing.T) {
+			m := v1.Manifest{Annotations: map[string]string{tt.key: tt.value}}
+			a := Of(&m.Annotations)
+			if err := tt.get(a); err == nil || errors.Is(err, ErrNotSet) {
+				t.Errorf("expected malformed value error, got %v", err)
+			}
+			if err := a.Validate(); err == nil {
+				t.Error("expected Validate to fail")
+			}
+		})
+	}
+}
+
+func TestUnsupportedBaseImageDigest(t *testing.T) {
+	idx := v1.Index{Annotations: map[string]string{
+		v1.AnnotationBaseImageDigest: "sha1:da39a3ee5e6b4b0d3255bfef95601890afd80709",
+	}}
+	if _, err := Of(&idx.Annotations).BaseImageDigest(); err != nil {
+		t.Errorf("unsupported algorithms should be accepted: %v", err)
+	}
+}
+
+func TestLicenseIDs(t *testing.T) {
+	manifest := v1.Manifest{Annotations: map[string]string{v1.AnnotationLicenses: "MIT OR Apache-2.0"}}
+	config := v1.Image{Config: v1.ImageConfig{Labels: map[string]string{v1.AnnotationLicenses: "bsd-3-clause AND MIT"}}}
+
+	ids, err := LicenseIDs(manifest.Annotations, config.Config.Labels, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Apache-2.0", "BSD-3-Clause", "MIT"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("unexpected license IDs: %v != %v", ids, expected)
+	}
+
+	config.Config.Labels[v1.AnnotationLicenses] = "GPL"
+	if _, err := LicenseIDs(manifest.Annotations, config.Config.Labels); err == nil {
+		t.Error("expected unknown license identifier to fail")
+	}
+}
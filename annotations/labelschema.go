@@ -0,0 +1,108 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// LabelSchemaPrefix is the key prefix used by Label Schema labels.
+const LabelSchemaPrefix = "org.label-schema."
+
+// ociPrefix is the key prefix reserved for the OCI Image Specification.
+const ociPrefix = "org.opencontainers.image."
+
+// labelSchemaKeys maps Label Schema keys to their compatible pre-defined
+// annotation keys, as listed in the "Back-compatibility with Label Schema"
+// section of annotations.md.
+var labelSchemaKeys = map[string]string{
+	LabelSchemaPrefix + "build-date":  v1.AnnotationCreated,
+	LabelSchemaPrefix + "url":         v1.AnnotationURL,
+	LabelSchemaPrefix + "vcs-url":     v1.AnnotationSource,
+	LabelSchemaPrefix + "version":     v1.AnnotationVersion,
+	LabelSchemaPrefix + "vcs-ref":     v1.AnnotationRevision,
+	LabelSchemaPrefix + "vendor":      v1.AnnotationVendor,
+	LabelSchemaPrefix + "name":        v1.AnnotationTitle,
+	LabelSchemaPrefix + "description": v1.AnnotationDescription,
+	LabelSchemaPrefix + "usage":       v1.AnnotationDocumentation,
+}
+
+// ociKeys is the reverse of labelSchemaKeys.
+var ociKeys = func() map[string]string {
+	m := make(map[string]string, len(labelSchemaKeys))
+	for ls, oci := range labelSchemaKeys {
+		m[oci] = ls
+	}
+	return m
+}()
+
+// FromLabelSchema translates the `org.label-schema.*` keys of m to their
+// `org.opencontainers.image.*` equivalents. All other keys are copied
+// unchanged, so the result of applying it to ImageConfig.Labels may be used
+// as manifest annotations. A key is left untranslated if m already has a
+// value for its pre-defined equivalent.
+//
+// Label Schema keys that have no equivalent, such as `schema-version`, are
+// copied unchanged and returned, sorted, in unmapped. `usage` is only
+// translated when its value is an absolute URL.
+func FromLabelSchema(m map[string]string) (out map[string]string, unmapped []string) {
+	return translate(m, LabelSchemaPrefix, labelSchemaKeys, func(from, value string) bool {
+		if from != LabelSchemaPrefix+"usage" {
+			return true
+		}
+		u, err := url.Parse(value)
+		return err == nil && u.IsAbs()
+	})
+}
+
+// ToLabelSchema translates the `org.opencontainers.image.*` keys of m to their
+// `org.label-schema.*` equivalents. All other keys are copied unchanged. A
+// key is left untranslated if m already has a value for its Label Schema
+// equivalent.
+//
+// Pre-defined keys that have no equivalent, such as AnnotationAuthors, are
+// copied unchanged and returned, sorted, in unmapped.
+func ToLabelSchema(m map[string]string) (out map[string]string, unmapped []string) {
+	return translate(m, ociPrefix, ociKeys, func(string, string) bool { return true })
+}
+
+func translate(m map[string]string, prefix string, keys map[string]string, compatible func(from, value string) bool) (map[string]string, []string) {
+	if m == nil {
+		return nil, nil
+	}
+	out := make(map[string]string, len(m))
+	var unmapped []string
+	for k, v := range m {
+		to, ok := keys[k]
+		if !ok || !compatible(k, v) {
+			if strings.HasPrefix(k, prefix) {
+				unmapped = append(unmapped, k)
+			}
+			out[k] = v
+			continue
+		}
+		if _, exists := m[to]; exists {
+			out[k] = v
+			continue
+		}
+		out[to] = v
+	}
+	sort.Strings(unmapped)
+	return out, unmapped
+}
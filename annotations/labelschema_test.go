@@ -0,0 +1,90 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestFromLabelSchema(t *testing.T) {
+	config := v1.ImageConfig{Labels: map[string]string{
+		"org.label-schema.build-date":     "2016-04-12T23:20:50.52Z",
+		"org.label-schema.vcs-ref":        "45a939b2999782a3f005621a8d0f29aa387e1d6b",
+		"org.label-schema.vcs-url":        "https://example.com/project.git",
+		"org.label-schema.usage":          "/usr/share/doc/README.md",
+		"org.label-schema.name":           "from-label-schema",
+		"org.label-schema.schema-version": "1.0",
+		"org.label-schema.docker.cmd":     "docker run -d example",
+		"org.opencontainers.image.title":  "title",
+		"com.example.key":                 "value",
+	}}
+
+	out, unmapped := FromLabelSchema(config.Labels)
+	expected := map[string]string{
+		v1.AnnotationCreated:              "2016-04-12T23:20:50.52Z",
+		v1.AnnotationRevision:             "45a939b2999782a3f005621a8d0f29aa387e1d6b",
+		v1.AnnotationSource:               "https://example.com/project.git",
+		"org.label-schema.usage":          "/usr/share/doc/README.md",
+		"org.label-schema.name":           "from-label-schema",
+		"org.label-schema.schema-version": "1.0",
+		"org.label-schema.docker.cmd":     "docker run -d example",
+		v1.AnnotationTitle:                "title",
+		"com.example.key":                 "value",
+	}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("unexpected annotations: %v", out)
+	}
+	expectedUnmapped := []string{
+		"org.label-schema.docker.cmd",
+		"org.label-schema.schema-version",
+		"org.label-schema.usage",
+	}
+	if !reflect.DeepEqual(unmapped, expectedUnmapped) {
+		t.Errorf("unexpected unmapped keys: %v", unmapped)
+	}
+}
+
+func TestToLabelSchema(t *testing.T) {
+	manifest := v1.Manifest{Annotations: map[string]string{
+		v1.AnnotationCreated:       "2016-04-12T23:20:50.52Z",
+		v1.AnnotationDocumentation: "https://example.com/docs",
+		v1.AnnotationVendor:        "Example",
+		v1.AnnotationLicenses:      "MIT",
+		v1.AnnotationRefName:       "latest",
+	}}
+
+	out, unmapped := ToLabelSchema(manifest.Annotations)
+	expected := map[string]string{
+		"org.label-schema.build-date": "2016-04-12T23:20:50.52Z",
+		"org.label-schema.usage":      "https://example.com/docs",
+		"org.label-schema.vendor":     "Example",
+		v1.AnnotationLicenses:         "MIT",
+		v1.AnnotationRefName:          "latest",
+	}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("unexpected labels: %v", out)
+	}
+	if expectedUnmapped := []string{v1.AnnotationLicenses, v1.AnnotationRefName}; !reflect.DeepEqual(unmapped, expectedUnmapped) {
+		t.Errorf("unexpected unmapped keys: %v", unmapped)
+	}
+
+	back, _ := FromLabelSchema(out)
+	if !reflect.DeepEqual(back, manifest.Annotations) {
+		t.Errorf("round trip mismatch: %v", back)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opencontainers/image-spec/spdx"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Lint checks the document blob of the given media type, a descriptor,
+// manifest, index or image configuration, for problems that the
+// specification does not require a consumer to reject, such as pre-defined
+// annotations whose value does not follow the documented format. The
+// document is expected to be valid; all findings are returned in a single
+// error. Documents of other media types are not checked.
+func Lint(mediaType string, blob []byte) error {
+	rule, ok := lintByMediaType[mediaType]
+	if !ok {
+		return nil
+	}
+	errs := rule(blob)
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+type lintFunc func([]byte) []error
+
+var lintByMediaType = map[string]lintFunc{
+	v1.MediaTypeDescriptor:    lintDescriptorLicenses,
+	v1.MediaTypeImageManifest: lintManifestLicenses,
+	v1.MediaTypeImageIndex:    lintIndexLicenses,
+	v1.MediaTypeImageConfig:   lintConfigLicenses,
+}
+
+func lintDescriptorLicenses(buf []byte) []error {
+	var header v1.Descriptor
+	if err := json.Unmarshal(buf, &header); err != nil {
+		return []error{fmt.Errorf("descriptor format mismatch: %w", err)}
+	}
+	return lintLicenses("annotations", header.Annotations)
+}
+
+func lintManifestLicenses(buf []byte) []error {
+	var header v1.Manifest
+	if err := json.Unmarshal(buf, &header); err != nil {
+		return []error{fmt.Errorf("manifest format mismatch: %w", err)}
+	}
+	errs := lintLicenses("annotations", header.Annotations)
+	errs = append(errs, lintLicenses("config.annotations", header.Config.Annotations)...)
+	for i, layer := range header.Layers {
+		errs = append(errs, lintLicenses(fmt.Sprintf("layers[%d].annotations", i), layer.Annotations)...)
+	}
+	return errs
+}
+
+func lintIndexLicenses(buf []byte) []error {
+	var header v1.Index
+	if err := json.Unmarshal(buf, &header); err != nil {
+		return []error{fmt.Errorf("index format mismatch: %w", err)}
+	}
+	errs := lintLicenses("annotations", header.Annotations)
+	for i, manifest := range header.Manifests {
+		errs = append(errs, lintLicenses(fmt.Sprintf("manifests[%d].annotations", i), manifest.Annotations)...)
+	}
+	return errs
+}
+
+func lintConfigLicenses(buf []byte) []error {
+	var header v1.Image
+	if err := json.Unmarshal(buf, &header); err != nil {
+		return []error{fmt.Errorf("config format mismatch: %w", err)}
+	}
+	return lintLicenses("config.Labels", header.Config.Labels)
+}
+
+// lintLicenses checks that AnnotationLicenses in m, if present, is a valid
+// SPDX license expression using identifiers from the SPDX License List.
+func lintLicenses(field string, m map[string]string) []error {
+	e, err := Of(&m).Licenses()
+	if errors.Is(err, ErrNotSet) {
+		return nil
+	}
+	if err != nil {
+		return []error{fmt.Errorf("%s: %w", field, err)}
+	}
+	if err := spdx.Validate(e); err != nil {
+		return []error{fmt.Errorf("%s: %s: %w", field, v1.AnnotationLicenses, err)}
+	}
+	return nil
+}
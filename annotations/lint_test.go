@@ -0,0 +1,128 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestLintLicenses(t *testing.T) {
+	for i, tt := range []struct {
+		mediaType string
+		input     string
+		fail      bool
+	}{
+		// valid license expression on a manifest
+		{
+			mediaType: v1.MediaTypeImageManifest,
+			input: `
+{
+  "schemaVersion": 2,
+  "config": {
+    "mediaType": "application/vnd.oci.image.config.v1+json",
+    "size": 1470,
+    "digest": "sha256:c86f7763873b6c0aae22d963bab59b4f5debbed6685761b5951584f6efb0633b"
+  },
+  "layers": [],
+  "annotations": {
+    "org.opencontainers.image.licenses": "Apache-2.0 OR MIT"
+  }
+}
+`,
+		},
+
+		// expected failure: not an SPDX expression
+		{
+			mediaType: v1.MediaTypeImageManifest,
+			input: `
+{
+  "schemaVersion": 2,
+  "config": {
+    "mediaType": "application/vnd.oci.image.config.v1+json",
+    "size": 1470,
+    "digest": "sha256:c86f7763873b6c0aae22d963bab59b4f5debbed6685761b5951584f6efb0633b"
+  },
+  "layers": [],
+  "annotations": {
+    "org.opencontainers.image.licenses": "Apache 2"
+  }
+}
+`,
+			fail: true,
+		},
+
+		// expected failure: unknown license identifier on an index entry
+		{
+			mediaType: v1.MediaTypeImageIndex,
+			input: `
+{
+  "schemaVersion": 2,
+  "manifests": [
+    {
+      "mediaType": "application/vnd.oci.image.manifest.v1+json",
+      "size": 7143,
+      "digest": "sha256:e692418e4cbaf90ca69d05a66403747baa33ee08806650b51fab815ad7fc331f",
+      "annotations": {
+        "org.opencontainers.image.licenses": "GPL"
+      }
+    }
+  ]
+}
+`,
+			fail: true,
+		},
+
+		// expected failure: unknown license identifier in config labels
+		{
+			mediaType: v1.MediaTypeImageConfig,
+			input: `
+{
+  "architecture": "amd64",
+  "os": "linux",
+  "config": {
+    "Labels": {
+      "org.opencontainers.image.licenses": "GPL"
+    }
+  },
+  "rootfs": {
+    "diff_ids": [],
+    "type": "layers"
+  }
+}
+`,
+			fail: true,
+		},
+
+		// no license annotation on a descriptor
+		{
+			mediaType: v1.MediaTypeDescriptor,
+			input: `
+{
+  "mediaType": "application/vnd.oci.image.manifest.v1+json",
+  "size": 7682,
+  "digest": "sha256:5b0bcabd1ed22e9fb1310cf6c2dec7cdef19f0ad69efa1f392e94a4333501270"
+}
+`,
+		},
+	} {
+		err := Lint(tt.mediaType, []byte(tt.input))
+
+		if got := err != nil; tt.fail != got {
+			t.Errorf("test %d: expected lint failure %t but got %t, err %v", i, tt.fail, got, err)
+		}
+	}
+}
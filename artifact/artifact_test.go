@@ -0,0 +1,271 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact
+
+import (
+	"bytes"
+	"context"
+	_ "crypto/sha256" // required to install sha256 digest support
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/builder"
+	"github.com/opencontainers/image-spec/content"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// readManifest returns the manifest of desc in store.
+func readManifest(t *testing.T, store *content.Memory, desc v1.Descriptor) v1.Manifest {
+	t.Helper()
+	blob, err := content.ReadAll(context.Background(), store, desc, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m v1.Manifest
+	if err := json.Unmarshal(blob, &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// exists returns whether the content of desc is in store.
+func exists(t *testing.T, store *content.Memory, desc v1.Descriptor) bool {
+	t.Helper()
+	ok, err := store.Exists(context.Background(), desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ok
+}
+
+// corruptFetcher returns corrupt content for the blob of digest.
+type corruptFetcher struct {
+	content.Fetcher
+	digest digest.Digest
+}
+
+func (f corruptFetcher) Fetch(ctx context.Context, desc v1.Descriptor) (io.ReadCloser, error) {
+	if desc.Digest == f.digest {
+		return io.NopCloser(strings.NewReader("corrupt")), nil
+	}
+	return f.Fetcher.Fetch(ctx, desc)
+}
+
+func TestPackUnpack(t *testing.T) {
+	fsys := fstest.MapFS{
+		"src/README.md":      {Data: []byte("# Example\n")},
+		"src/data/sbom.json": {Data: []byte(`{"spdxVersion":"SPDX-2.3"}`)},
+		"src/data/empty":     {Data: []byte{}},
+		"other":              {Data: []byte("not packed")},
+	}
+	store := content.NewMemory()
+	subject := v1.DescriptorEmptyJSON
+	desc, err := PackFS(context.Background(), store, fsys, "src", Options{
+		ArtifactType: "application/vnd.example+type",
+		MediaType: func(name string) string {
+			if strings.HasSuffix(name, ".json") {
+				return "application/spdx+json"
+			}
+			return ""
+		},
+		Subject:     &subject,
+		Annotations: map[string]string{"com.example.key": "value"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.ArtifactType != "application/vnd.example+type" {
+		t.Errorf("unexpected descriptor: %+v", desc)
+	}
+
+	m := readManifest(t, store, desc)
+	if m.Config.MediaType != v1.MediaTypeEmptyJSON || m.Config.Digest != v1.DescriptorEmptyJSON.Digest {
+		t.Errorf("unexpected config: %+v", m.Config)
+	}
+	if !exists(t, store, v1.DescriptorEmptyJSON) {
+		t.Error("empty config was not pushed")
+	}
+	if m.Subject == nil || m.Subject.Digest != subject.Digest || m.Annotations["com.example.key"] != "value" {
+		t.Errorf("unexpected manifest: %+v", m)
+	}
+	var layers []string
+	for _, l := range m.Layers {
+		layers = append(layers, l.Annotations[v1.AnnotationTitle]+" "+l.MediaType)
+		if !exists(t, store, l) {
+			t.Errorf("layer %s was not pushed", l.Digest)
+		}
+	}
+	expected := []string{
+		"README.md " + DefaultMediaType,
+		"data/empty " + DefaultMediaType,
+		"data/sbom.json application/spdx+json",
+	}
+	if !reflect.DeepEqual(layers, expected) {
+		t.Errorf("unexpected layers: %q", layers)
+	}
+
+	dir := filepath.Join(t.TempDir(), "out")
+	names, err := Unpack(context.Background(), store, desc, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"README.md", "data/empty", "data/sbom.json"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("unexpected names: %q", names)
+	}
+	for _, name := range names {
+		got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, fsys["src/"+name].Data) {
+			t.Errorf("unexpected content of %s: %q", name, got)
+		}
+	}
+
+	// existing files are not overwritten
+	if _, err := Unpack(context.Background(), store, desc, dir); err == nil {
+		t.Error("expected unpacking over existing files to fail")
+	}
+}
+
+func TestPackEmpty(t *testing.T) {
+	store := content.NewMemory()
+	desc, err := Pack(context.Background(), store, nil, Options{ArtifactType: "application/vnd.example+type"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := readManifest(t, store, desc)
+	if len(m.Layers) != 1 || m.Layers[0].Digest != v1.DescriptorEmptyJSON.Digest {
+		t.Errorf("unexpected layers: %+v", m.Layers)
+	}
+
+	names, err := Unpack(context.Background(), store, desc, t.TempDir())
+	if err != nil || len(names) != 0 {
+		t.Errorf("unexpected unpack result: %q, %v", names, err)
+	}
+}
+
+func TestPackInvalid(t *testing.T) {
+	file := func(name string) File {
+		return File{Name: name, Open: func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(name)), nil
+		}}
+	}
+	for _, tt := range []struct {
+		name  string
+		files []File
+		opts  Options
+	}{
+		{name: "no artifact type", files: []File{file("a")}},
+		{name: "traversal", files: []File{file("../a")}},
+		{name: "absolute", files: []File{file("/a")}},
+		{name: "backslash", files: []File{file(`a\..\..\b`)}},
+		{name: "duplicate", files: []File{file("a"), file("a")}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			if tt.name != "no artifact type" {
+				opts.ArtifactType = "application/vnd.example+type"
+			}
+			if _, err := Pack(context.Background(), content.NewMemory(), tt.files, opts); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+// pushManifest pushes a manifest with arbitrary titles, bypassing the checks
+// of Pack.
+func pushManifest(t *testing.T, store *content.Memory, layers map[string]string) v1.Descriptor {
+	t.Helper()
+	m := builder.NewManifest().Validate(builder.NoValidation).ArtifactType("application/vnd.example+type")
+	for title, data := range layers {
+		desc, err := builder.Describe(digest.Canonical, DefaultMediaType, strings.NewReader(data), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		desc.Annotations = map[string]string{v1.AnnotationTitle: title}
+		if err := store.Push(context.Background(), desc, strings.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+		m.LayerDescriptor(desc)
+	}
+	res, err := m.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Push(context.Background(), res.Descriptor, bytes.NewReader(res.Bytes)); err != nil {
+		t.Fatal(err)
+	}
+	return res.Descriptor
+}
+
+func TestUnpackUnsafe(t *testing.T) {
+	for _, title := range []string{"../escape", "/etc/escape", "a/../../escape", "", "."} {
+		t.Run(title, func(t *testing.T) {
+			store := content.NewMemory()
+			desc := pushManifest(t, store, map[string]string{"ok": "ok", title: "escape"})
+			root := t.TempDir()
+			dir := filepath.Join(root, "out")
+			if _, err := Unpack(context.Background(), store, desc, dir); err == nil {
+				t.Fatal("expected error")
+			}
+			if _, err := os.Stat(dir); !os.IsNotExist(err) {
+				t.Errorf("files were written before the titles were checked: %v", err)
+			}
+		})
+	}
+
+	t.Run("symlink", func(t *testing.T) {
+		store := content.NewMemory()
+		desc := pushManifest(t, store, map[string]string{"link/escape": "escape"})
+		root := t.TempDir()
+		outside := filepath.Join(root, "outside")
+		dir := filepath.Join(root, "out")
+		for _, d := range []string{outside, dir} {
+			if err := os.Mkdir(d, 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+			t.Skip(err)
+		}
+		if _, err := Unpack(context.Background(), store, desc, dir); err == nil {
+			t.Fatal("expected error")
+		}
+		if _, err := os.Stat(filepath.Join(outside, "escape")); !os.IsNotExist(err) {
+			t.Errorf("file was written through a symbolic link: %v", err)
+		}
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		store := content.NewMemory()
+		desc := pushManifest(t, store, map[string]string{"file": "content"})
+		dir := t.TempDir()
+		if _, err := Unpack(context.Background(), corruptFetcher{store, digest.FromString("content")}, desc, dir); err == nil {
+			t.Fatal("expected error")
+		}
+		if _, err := os.Stat(filepath.Join(dir, "file")); !os.IsNotExist(err) {
+			t.Errorf("corrupt file was kept: %v", err)
+		}
+	})
+}
@@ -0,0 +1,211 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package artifact packages files as artifacts, following the guidelines for
+// artifact usage of the image manifest specification: the manifest has an
+// artifactType and the empty config, and each file is stored as a layer
+// named by its org.opencontainers.image.title annotation.
+package artifact
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/builder"
+	"github.com/opencontainers/image-spec/content"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// DefaultMediaType is the media type of the layers of files without one.
+const DefaultMediaType = "application/octet-stream"
+
+// File is a file of an artifact.
+type File struct {
+	// Name is the slash-separated relative path of the file, stored in the
+	// title annotation of its layer.
+	Name string
+
+	// MediaType is the media type of the layer of the file.
+	MediaType string
+
+	// Open opens the content of the file. It is called twice by Pack, to
+	// digest the content and then to push it.
+	Open func() (io.ReadCloser, error)
+}
+
+// Options are the options of Pack and PackFS.
+type Options struct {
+	// ArtifactType is the type of the artifact. It is required.
+	ArtifactType string
+
+	// MediaType returns the media type of the layer of a file without one.
+	// If it is nil or returns an empty string, DefaultMediaType is used.
+	MediaType func(name string) string
+
+	// Subject is the manifest the artifact refers to, if any.
+	Subject *v1.Descriptor
+
+	// Annotations are the annotations of the manifest.
+	Annotations map[string]string
+
+	// Algorithm digests the layers and the manifest. It defaults to
+	// digest.Canonical.
+	Algorithm digest.Algorithm
+
+	// Validator validates the manifest before it is pushed. It is typically
+	// schema.ValidatorMediaTypeManifest. If nil, the manifest is not
+	// validated, as with builder.NoValidation.
+	Validator builder.Validator
+}
+
+// Pack pushes files as the layers of an artifact manifest, in order, then
+// pushes the manifest and returns its descriptor. An artifact without files
+// has a single empty layer, as recommended by the specification.
+func Pack(ctx context.Context, p content.Pusher, files []File, opts Options) (v1.Descriptor, error) {
+	if opts.ArtifactType == "" {
+		return v1.Descriptor{}, errors.New("artifact type is required")
+	}
+	alg := opts.Algorithm
+	if alg == "" {
+		alg = digest.Canonical
+	}
+
+	validator := opts.Validator
+	if validator == nil {
+		validator = builder.NoValidation
+	}
+	m := builder.NewManifest().Algorithm(alg).ArtifactType(opts.ArtifactType).Validate(validator)
+	if opts.Subject != nil {
+		m.Subject(*opts.Subject)
+	}
+	for k, v := range opts.Annotations {
+		m.Annotation(k, v)
+	}
+
+	names := make(map[string]bool, len(files))
+	for _, f := range files {
+		if err := checkName(f.Name); err != nil {
+			return v1.Descriptor{}, err
+		}
+		if names[f.Name] {
+			return v1.Descriptor{}, fmt.Errorf("duplicate file %q", f.Name)
+		}
+		names[f.Name] = true
+
+		mediaType := f.MediaType
+		if mediaType == "" && opts.MediaType != nil {
+			mediaType = opts.MediaType(f.Name)
+		}
+		if mediaType == "" {
+			mediaType = DefaultMediaType
+		}
+		desc, err := describe(alg, mediaType, f)
+		if err != nil {
+			return v1.Descriptor{}, err
+		}
+		desc.Annotations = map[string]string{v1.AnnotationTitle: f.Name}
+		if err := push(ctx, p, desc, f); err != nil {
+			return v1.Descriptor{}, err
+		}
+		m.LayerDescriptor(desc)
+	}
+
+	if err := p.Push(ctx, v1.DescriptorEmptyJSON, bytes.NewReader(v1.DescriptorEmptyJSON.Data)); err != nil {
+		return v1.Descriptor{}, fmt.Errorf("pushing empty config: %w", err)
+	}
+	if len(files) == 0 {
+		m.LayerDescriptor(v1.DescriptorEmptyJSON)
+	}
+
+	res, err := m.Build()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	if err := p.Push(ctx, res.Descriptor, bytes.NewReader(res.Bytes)); err != nil {
+		return v1.Descriptor{}, fmt.Errorf("pushing manifest: %w", err)
+	}
+	return res.Descriptor, nil
+}
+
+// PackFS packs the regular files of the tree rooted at root in fsys, in
+// lexical order, as with Pack. Files are named by their path relative to
+// root, and directories are not recorded. Other types of files, such as
+// symbolic links, are rejected.
+func PackFS(ctx context.Context, p content.Pusher, fsys fs.FS, root string, opts Options) (v1.Descriptor, error) {
+	var files []File
+	err := fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return fmt.Errorf("%s: unsupported file type %s", name, d.Type())
+		}
+		rel := name
+		if root != "." {
+			rel = strings.TrimPrefix(name, root+"/")
+		}
+		files = append(files, File{
+			Name: rel,
+			Open: func() (io.ReadCloser, error) { return fsys.Open(name) },
+		})
+		return nil
+	})
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	return Pack(ctx, p, files, opts)
+}
+
+func describe(alg digest.Algorithm, mediaType string, f File) (v1.Descriptor, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	defer rc.Close()
+	desc, err := builder.Describe(alg, mediaType, rc, -1)
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("%s: %w", f.Name, err)
+	}
+	return desc, nil
+}
+
+func push(ctx context.Context, p content.Pusher, desc v1.Descriptor, f File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := p.Push(ctx, desc, rc); err != nil {
+		return fmt.Errorf("pushing %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// checkName checks that name is a slash-separated relative path that stays
+// within the directory an artifact is unpacked to.
+func checkName(name string) error {
+	if !fs.ValidPath(name) || name == "." || strings.Contains(name, `\`) {
+		return fmt.Errorf("invalid file name %q", name)
+	}
+	return nil
+}
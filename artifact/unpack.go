@@ -0,0 +1,127 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/image-spec/content"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// maxManifestSize is the largest manifest Unpack reads.
+const maxManifestSize = 4 << 20
+
+// Unpack writes the layers of the artifact manifest described by desc to
+// files of dir named by their title annotation, and returns the names of the
+// written files. Layers without a title, such as the empty layer of an
+// artifact without files, are skipped.
+//
+// Titles that are not relative paths within dir are rejected before any file
+// is written. Existing files are never overwritten, nor are symbolic links
+// followed within dir, and a file whose content does not match its layer is
+// removed.
+func Unpack(ctx context.Context, f content.Fetcher, desc v1.Descriptor, dir string) ([]string, error) {
+	raw, err := content.ReadAll(ctx, f, desc, maxManifestSize)
+	if err != nil {
+		return nil, fmt.Errorf("fetching manifest: %w", err)
+	}
+	var manifest v1.Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing manifest %s: %w", desc.Digest, err)
+	}
+
+	var (
+		names  []string
+		layers []v1.Descriptor
+		seen   = map[string]bool{}
+	)
+	for _, layer := range manifest.Layers {
+		name, ok := layer.Annotations[v1.AnnotationTitle]
+		if !ok {
+			continue
+		}
+		if err := checkName(name); err != nil {
+			return nil, err
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate file %q", name)
+		}
+		seen[name] = true
+		names = append(names, name)
+		layers = append(layers, layer)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	for i, name := range names {
+		if err := unpackFile(ctx, f, layers[i], dir, name); err != nil {
+			return names[:i], err
+		}
+	}
+	return names, nil
+}
+
+func unpackFile(ctx context.Context, f content.Fetcher, desc v1.Descriptor, dir, name string) (err error) {
+	parent := dir
+	elems := strings.Split(name, "/")
+	for _, elem := range elems[:len(elems)-1] {
+		parent = filepath.Join(parent, elem)
+		if err := mkdir(parent); err != nil {
+			return err
+		}
+	}
+
+	target := filepath.Join(parent, elems[len(elems)-1])
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(target)
+		}
+	}()
+	if err := content.Copy(ctx, file, f, desc); err != nil {
+		return fmt.Errorf("unpacking %s: %w", name, err)
+	}
+	return nil
+}
+
+// mkdir creates the directory path, or checks that it is a directory and not
+// a symbolic link to one.
+func mkdir(path string) error {
+	fi, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return os.Mkdir(path, 0o755)
+	}
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s: not a directory", path)
+	}
+	return nil
+}
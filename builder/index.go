@@ -0,0 +1,310 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Index assembles an image index from the manifests of the images built for
+// each platform, and the manifests attached to them. Methods record the
+// first error they encounter, which is returned by Build; calls following an
+// error have no effect.
+//
+// The entries of the index are ordered deterministically, independently of
+// the order in which they are added: manifests with a platform come first,
+// ordered by platform, followed by the other manifests, with attached
+// manifests grouped after the manifest they refer to.
+//
+// The zero value is not usable, use NewIndex.
+type Index struct {
+	algorithm   digest.Algorithm
+	validator   Validator
+	entries     []indexEntry
+	annotations map[string]string
+	err         error
+}
+
+type indexEntry struct {
+	desc    v1.Descriptor
+	subject digest.Digest
+}
+
+// NewIndex returns a builder for an index with the media type
+// v1.MediaTypeImageIndex, digested with digest.Canonical. A validator must be
+// set with Validate before Build.
+func NewIndex() *Index {
+	return &Index{algorithm: digest.Canonical}
+}
+
+// Algorithm sets the algorithm used to digest the manifests added
+// afterwards and the index itself.
+func (x *Index) Algorithm(alg digest.Algorithm) *Index {
+	if x.err == nil && !alg.Available() {
+		x.err = fmt.Errorf("digest algorithm %q is not available", alg)
+	}
+	x.algorithm = alg
+	return x
+}
+
+// Validate sets the validator applied to the index by Build. It is
+// typically schema.ValidatorMediaTypeImageIndex, or NoValidation.
+func (x *Index) Validate(v Validator) *Index {
+	x.validator = v
+	return x
+}
+
+// Manifest adds a manifest by its descriptor, which should have a platform
+// if it is the manifest of a runnable image.
+func (x *Index) Manifest(desc v1.Descriptor) *Index {
+	return x.add(indexEntry{desc: desc})
+}
+
+// Image adds the serialized manifest of an image, with the platform of the
+// entry taken from the image configuration config. The configuration must
+// match the config descriptor of the manifest.
+func (x *Index) Image(manifest, config []byte) *Index {
+	if x.err != nil {
+		return x
+	}
+	var m v1.Manifest
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		x.err = fmt.Errorf("parsing manifest: %w", err)
+		return x
+	}
+	if err := m.Config.Digest.Validate(); err != nil {
+		x.err = fmt.Errorf("manifest config: %w", err)
+		return x
+	}
+	if int64(len(config)) != m.Config.Size || m.Config.Digest.Algorithm().FromBytes(config) != m.Config.Digest {
+		x.err = fmt.Errorf("config does not match manifest config %s", m.Config.Digest)
+		return x
+	}
+	var img v1.Image
+	if err := json.Unmarshal(config, &img); err != nil {
+		x.err = fmt.Errorf("parsing config %s: %w", m.Config.Digest, err)
+		return x
+	}
+	if img.OS == "" || img.Architecture == "" {
+		x.err = fmt.Errorf("config %s has no platform", m.Config.Digest)
+		return x
+	}
+
+	desc := x.describe(m.MediaType, manifest)
+	platform := img.Platform
+	desc.Platform = &platform
+	return x.add(indexEntry{desc: desc})
+}
+
+// Attach adds a serialized manifest that refers to another manifest of the
+// index with its subject field, such as an attestation or a signature. The
+// entry has the artifact type of the manifest, which is its artifactType
+// field or else the media type of its config.
+func (x *Index) Attach(manifest []byte) *Index {
+	if x.err != nil {
+		return x
+	}
+	var m v1.Manifest
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		x.err = fmt.Errorf("parsing manifest: %w", err)
+		return x
+	}
+	if m.Subject == nil {
+		x.err = errors.New("attached manifest has no subject")
+		return x
+	}
+
+	desc := x.describe(m.MediaType, manifest)
+	desc.ArtifactType = m.ArtifactType
+	if desc.ArtifactType == "" {
+		desc.ArtifactType = m.Config.MediaType
+	}
+	return x.add(indexEntry{desc: desc, subject: m.Subject.Digest})
+}
+
+// Annotation sets an annotation of the index.
+func (x *Index) Annotation(key, value string) *Index {
+	if x.annotations == nil {
+		x.annotations = map[string]string{}
+	}
+	x.annotations[key] = value
+	return x
+}
+
+func (x *Index) describe(mediaType string, manifest []byte) v1.Descriptor {
+	if mediaType == "" {
+		mediaType = v1.MediaTypeImageManifest
+	}
+	return v1.Descriptor{
+		MediaType: mediaType,
+		Digest:    x.algorithm.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+}
+
+// add adds an entry, ignoring exact duplicates and rejecting entries that
+// conflict with an existing one, either by describing the same manifest
+// differently or by being another manifest for the same platform.
+func (x *Index) add(e indexEntry) *Index {
+	if x.err != nil {
+		return x
+	}
+	for _, other := range x.entries {
+		if other.desc.Digest == e.desc.Digest {
+			if !reflect.DeepEqual(other, e) {
+				x.err = fmt.Errorf("conflicting descriptors for manifest %s", e.desc.Digest)
+			}
+			return x
+		}
+		if e.desc.Platform != nil && other.desc.Platform != nil && comparePlatforms(e.desc.Platform, other.desc.Platform) == 0 {
+			x.err = fmt.Errorf("manifests %s and %s have the same platform %s", other.desc.Digest, e.desc.Digest, formatPlatform(e.desc.Platform))
+			return x
+		}
+	}
+	x.entries = append(x.entries, e)
+	return x
+}
+
+// Build returns the serialized index and its descriptor. The index is
+// checked by the validator set with Validate, and Build fails with
+// ErrNoValidator if there is none.
+func (x *Index) Build() (Result, error) {
+	if x.err != nil {
+		return Result{}, x.err
+	}
+	if x.validator == nil {
+		return Result{}, ErrNoValidator
+	}
+
+	manifests, err := x.sortedManifests()
+	if err != nil {
+		return Result{}, err
+	}
+	raw, err := json.Marshal(v1.Index{
+		Versioned:   specs.Versioned{SchemaVersion: 2},
+		MediaType:   v1.MediaTypeImageIndex,
+		Manifests:   manifests,
+		Annotations: x.annotations,
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	if err := x.validator.Validate(bytes.NewReader(raw)); err != nil {
+		return Result{}, fmt.Errorf("invalid index: %w", err)
+	}
+	return Result{
+		Bytes: raw,
+		Descriptor: v1.Descriptor{
+			MediaType: v1.MediaTypeImageIndex,
+			Digest:    x.algorithm.FromBytes(raw),
+			Size:      int64(len(raw)),
+		},
+	}, nil
+}
+
+// sortedManifests returns the descriptors of the entries in the order of
+// the index.
+func (x *Index) sortedManifests() ([]v1.Descriptor, error) {
+	var roots, attached []indexEntry
+	for _, e := range x.entries {
+		if e.subject == "" {
+			roots = append(roots, e)
+		} else {
+			attached = append(attached, e)
+		}
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		pi, pj := roots[i].desc.Platform, roots[j].desc.Platform
+		switch {
+		case pi != nil && pj == nil:
+			return true
+		case pi == nil && pj != nil:
+			return false
+		case pi != nil && pj != nil:
+			if c := comparePlatforms(pi, pj); c != 0 {
+				return c < 0
+			}
+		}
+		return roots[i].desc.Digest < roots[j].desc.Digest
+	})
+	sort.Slice(attached, func(i, j int) bool {
+		if attached[i].desc.ArtifactType != attached[j].desc.ArtifactType {
+			return attached[i].desc.ArtifactType < attached[j].desc.ArtifactType
+		}
+		return attached[i].desc.Digest < attached[j].desc.Digest
+	})
+
+	// attached manifests may refer to other attached manifests, such as a
+	// signature of an attestation, so they are placed depth first
+	manifests := make([]v1.Descriptor, 0, len(x.entries))
+	placed := make(map[digest.Digest]bool, len(x.entries))
+	var place func(e indexEntry)
+	place = func(e indexEntry) {
+		manifests = append(manifests, e.desc)
+		placed[e.desc.Digest] = true
+		for _, a := range attached {
+			if a.subject == e.desc.Digest && !placed[a.desc.Digest] {
+				place(a)
+			}
+		}
+	}
+	for _, e := range roots {
+		place(e)
+	}
+	for _, a := range attached {
+		if !placed[a.desc.Digest] {
+			return nil, fmt.Errorf("subject %s of attached manifest %s is not in the index", a.subject, a.desc.Digest)
+		}
+	}
+	return manifests, nil
+}
+
+// comparePlatforms orders platforms by operating system, architecture,
+// variant, operating system version and features.
+func comparePlatforms(a, b *v1.Platform) int {
+	for _, f := range [][2]string{
+		{a.OS, b.OS},
+		{a.Architecture, b.Architecture},
+		{a.Variant, b.Variant},
+		{a.OSVersion, b.OSVersion},
+		{strings.Join(a.OSFeatures, ","), strings.Join(b.OSFeatures, ",")},
+	} {
+		if c := strings.Compare(f[0], f[1]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func formatPlatform(p *v1.Platform) string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	if p.OSVersion != "" {
+		s += " " + p.OSVersion
+	}
+	return s
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type testImage struct {
+	manifest Result
+	config   []byte
+}
+
+func buildImage(t *testing.T, platform v1.Platform) testImage {
+	t.Helper()
+	config, err := json.Marshal(v1.Image{
+		Platform: platform,
+		RootFS:   v1.RootFS{Type: "layers"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := NewManifest().Validate(NoValidation).Config(v1.MediaTypeImageConfig, config).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testImage{res, config}
+}
+
+func buildAttachment(t *testing.T, artifactType string, subject v1.Descriptor) Result {
+	t.Helper()
+	res, err := NewManifest().Validate(NoValidation).ArtifactType(artifactType).Subject(subject).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestBuildIndex(t *testing.T) {
+	amd64 := buildImage(t, v1.Platform{OS: "linux", Architecture: "amd64"})
+	arm64 := buildImage(t, v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"})
+	armv7 := buildImage(t, v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"})
+	sbom := buildAttachment(t, "application/spdx+json", amd64.manifest.Descriptor)
+	signature := buildAttachment(t, "application/vnd.example.signature", sbom.Descriptor)
+	provenance := buildAttachment(t, "application/vnd.in-toto+json", amd64.manifest.Descriptor)
+	other := v1.Descriptor{
+		MediaType: v1.MediaTypeImageManifest,
+		Digest:    "sha256:6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b",
+		Size:      7143,
+	}
+
+	build := func(order []int) Result {
+		x := NewIndex().Validate(NoValidation).Annotation(v1.AnnotationRefName, "latest")
+		for _, i := range order {
+			switch i {
+			case 0:
+				x.Image(amd64.manifest.Bytes, amd64.config)
+			case 1:
+				x.Image(arm64.manifest.Bytes, arm64.config)
+			case 2:
+				x.Image(armv7.manifest.Bytes, armv7.config)
+			case 3:
+				x.Attach(sbom.Bytes)
+			case 4:
+				x.Attach(signature.Bytes)
+			case 5:
+				x.Attach(provenance.Bytes)
+			case 6:
+				x.Manifest(other)
+			}
+		}
+		// exact duplicates are ignored
+		x.Image(amd64.manifest.Bytes, amd64.config)
+		res, err := x.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	res := build([]int{0, 1, 2, 3, 4, 5, 6})
+	if reversed := build([]int{6, 5, 4, 3, 2, 1, 0}); !reflect.DeepEqual(res, reversed) {
+		t.Errorf("index depends on insertion order:\n%s\n%s", res.Bytes, reversed.Bytes)
+	}
+
+	var index v1.Index
+	if err := json.Unmarshal(res.Bytes, &index); err != nil {
+		t.Fatal(err)
+	}
+	if index.SchemaVersion != 2 || index.MediaType != v1.MediaTypeImageIndex || index.Annotations[v1.AnnotationRefName] != "latest" {
+		t.Errorf("unexpected index: %s", res.Bytes)
+	}
+
+	var got []string
+	for _, desc := range index.Manifests {
+		s := desc.Digest.String()
+		if desc.Platform != nil {
+			s = fmt.Sprintf("%s/%s/%s", desc.Platform.OS, desc.Platform.Architecture, desc.Platform.Variant)
+		} else if desc.ArtifactType != "" {
+			s = desc.ArtifactType
+		}
+		got = append(got, s)
+	}
+	expected := []string{
+		"linux/amd64/",
+		"application/spdx+json",
+		"application/vnd.example.signature",
+		"application/vnd.in-toto+json",
+		"linux/arm/v7",
+		"linux/arm64/v8",
+		other.Digest.String(),
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected entries:\n%q\nexpected:\n%q", got, expected)
+	}
+	if desc := index.Manifests[0]; desc.Digest != amd64.manifest.Descriptor.Digest || desc.Size != amd64.manifest.Descriptor.Size {
+		t.Errorf("unexpected descriptor: %+v", desc)
+	}
+}
+
+func TestBuildIndexErrors(t *testing.T) {
+	amd64 := buildImage(t, v1.Platform{OS: "linux", Architecture: "amd64"})
+	// another image for the same platform
+	amd64Other, err := NewManifest().Validate(NoValidation).
+		Config(v1.MediaTypeImageConfig, amd64.config).
+		Layer(v1.MediaTypeImageLayer, []byte("layer")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	noPlatform := buildImage(t, v1.Platform{})
+	orphan := buildAttachment(t, "application/spdx+json", v1.DescriptorEmptyJSON)
+
+	for _, tt := range []struct {
+		name  string
+		index *Index
+	}{
+		{
+			name:  "same platform",
+			index: NewIndex().Validate(NoValidation).Image(amd64.manifest.Bytes, amd64.config).Image(amd64Other.Bytes, amd64.config),
+		},
+		{
+			name: "conflicting descriptor",
+			index: NewIndex().Validate(NoValidation).Image(amd64.manifest.Bytes, amd64.config).Manifest(v1.Descriptor{
+				MediaType: v1.MediaTypeImageManifest,
+				Digest:    amd64.manifest.Descriptor.Digest,
+				Size:      amd64.manifest.Descriptor.Size,
+			}),
+		},
+		{
+			name:  "mismatched config",
+			index: NewIndex().Validate(NoValidation).Image(amd64.manifest.Bytes, amd64.config[1:]),
+		},
+		{
+			name:  "no platform",
+			index: NewIndex().Validate(NoValidation).Image(noPlatform.manifest.Bytes, noPlatform.config),
+		},
+		{
+			name:  "no subject",
+			index: NewIndex().Validate(NoValidation).Attach(amd64.manifest.Bytes),
+		},
+		{
+			name:  "subject not in index",
+			index: NewIndex().Validate(NoValidation).Image(amd64.manifest.Bytes, amd64.config).Attach(orphan.Bytes),
+		},
+		{
+			name:  "invalid manifest",
+			index: NewIndex().Validate(NoValidation).Image([]byte("{"), amd64.config),
+		},
+		{
+			name:  "no validator",
+			index: NewIndex().Image(amd64.manifest.Bytes, amd64.config),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.index.Build(); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package builder assembles image manifests and indexes from their content,
+// computing the descriptors of the referenced blobs and manifests instead of
+// requiring them to be filled in by hand.
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Validator validates a serialized document. The validators of the schema
+// package, such as schema.ValidatorMediaTypeManifest, implement it.
+type Validator interface {
+	Validate(src io.Reader) error
+}
+
+// ErrNoValidator is returned by Build when no validator is set.
+var ErrNoValidator = errors.New("no validator set, use NoValidation to skip validation")
+
+// NoValidation is a Validator accepting every document, for documents
+// validated elsewhere: Build then only checks what the builder constructs,
+// and invalid media types or annotations are not detected.
+var NoValidation Validator = noValidation{}
+
+type noValidation struct{}
+
+func (noValidation) Validate(io.Reader) error { return nil }
+
+// Result is a serialized document along with its descriptor.
+type Result struct {
+	// Bytes is the serialized document. Its digest and size are the ones of
+	// Descriptor, and it must be stored verbatim.
+	Bytes []byte
+
+	// Descriptor is the descriptor of Bytes.
+	Descriptor v1.Descriptor
+}
+
+// Manifest builds an image manifest. Methods record the first error they
+// encounter, which is returned by Build; calls following an error have no
+// effect.
+//
+// The zero value is not usable, use NewManifest.
+type Manifest struct {
+	algorithm   digest.Algorithm
+	inlineLimit int64
+	validator   Validator
+	manifest    v1.Manifest
+	hasConfig   bool
+	err         error
+}
+
+// NewManifest returns a builder for a manifest with the media type
+// v1.MediaTypeImageManifest, describing blobs with digest.Canonical. A
+// validator must be set with Validate before Build.
+func NewManifest() *Manifest {
+	return &Manifest{
+		algorithm:   digest.Canonical,
+		inlineLimit: -1,
+		manifest: v1.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: v1.MediaTypeImageManifest,
+		},
+	}
+}
+
+// Algorithm sets the algorithm used to digest the blobs added afterwards
+// and the manifest itself.
+func (m *Manifest) Algorithm(alg digest.Algorithm) *Manifest {
+	if m.err == nil && !alg.Available() {
+		m.err = fmt.Errorf("digest algorithm %q is not available", alg)
+	}
+	m.algorithm = alg
+	return m
+}
+
+// Inline embeds the content of blobs added afterwards in the data field of
+// their descriptor when their size is at most limit bytes. A limit lower
+// than zero disables embedding, which is the default.
+func (m *Manifest) Inline(limit int64) *Manifest {
+	m.inlineLimit = limit
+	return m
+}
+
+// Validate sets the validator applied to the manifest by Build. It is
+// typically schema.ValidatorMediaTypeManifest, or NoValidation.
+func (m *Manifest) Validate(v Validator) *Manifest {
+	m.validator = v
+	return m
+}
+
+// Config sets the configuration of the manifest to content of the given
+// media type.
+func (m *Manifest) Config(mediaType string, content []byte) *Manifest {
+	return m.ConfigFrom(mediaType, bytes.NewReader(content))
+}
+
+// ConfigFrom sets the configuration of the manifest to the content read
+// from r, until EOF.
+func (m *Manifest) ConfigFrom(mediaType string, r io.Reader) *Manifest {
+	desc, ok := m.describe(mediaType, r)
+	if ok {
+		m.ConfigDescriptor(desc)
+	}
+	return m
+}
+
+// ConfigDescriptor sets the configuration of the manifest to an already
+// computed descriptor.
+func (m *Manifest) ConfigDescriptor(desc v1.Descriptor) *Manifest {
+	m.manifest.Config = desc
+	m.hasConfig = true
+	return m
+}
+
+// Layer appends a layer of the given media type.
+func (m *Manifest) Layer(mediaType string, content []byte) *Manifest {
+	return m.LayerFrom(mediaType, bytes.NewReader(content))
+}
+
+// LayerFrom appends a layer with the content read from r, until EOF.
+func (m *Manifest) LayerFrom(mediaType string, r io.Reader) *Manifest {
+	desc, ok := m.describe(mediaType, r)
+	if ok {
+		m.LayerDescriptor(desc)
+	}
+	return m
+}
+
+// LayerDescriptor appends a layer with an already computed descriptor.
+func (m *Manifest) LayerDescriptor(desc v1.Descriptor) *Manifest {
+	m.manifest.Layers = append(m.manifest.Layers, desc)
+	return m
+}
+
+// ArtifactType sets the type of an artifact manifest. If no configuration
+// is set, Build uses v1.DescriptorEmptyJSON as recommended for artifacts.
+func (m *Manifest) ArtifactType(artifactType string) *Manifest {
+	m.manifest.ArtifactType = artifactType
+	return m
+}
+
+// Subject sets the manifest the built manifest refers to.
+func (m *Manifest) Subject(desc v1.Descriptor) *Manifest {
+	m.manifest.Subject = &desc
+	return m
+}
+
+// Annotation sets an annotation of the manifest.
+func (m *Manifest) Annotation(key, value string) *Manifest {
+	if m.manifest.Annotations == nil {
+		m.manifest.Annotations = map[string]string{}
+	}
+	m.manifest.Annotations[key] = value
+	return m
+}
+
+// Build returns the serialized manifest and its descriptor. The manifest is
+// checked by the validator set with Validate, and Build fails with
+// ErrNoValidator if there is none.
+func (m *Manifest) Build() (Result, error) {
+	if m.err != nil {
+		return Result{}, m.err
+	}
+	if m.validator == nil {
+		return Result{}, ErrNoValidator
+	}
+	manifest := m.manifest
+	if !m.hasConfig {
+		if manifest.ArtifactType == "" {
+			return Result{}, errors.New("manifest has no config")
+		}
+		manifest.Config = v1.DescriptorEmptyJSON
+	}
+	if manifest.Layers == nil {
+		// layers must be present, and be an empty array for artifacts
+		// without blobs
+		manifest.Layers = []v1.Descriptor{}
+	}
+
+	raw, err := json.Marshal(manifest)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := m.validator.Validate(bytes.NewReader(raw)); err != nil {
+		return Result{}, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return Result{
+		Bytes: raw,
+		Descriptor: v1.Descriptor{
+			MediaType:    manifest.MediaType,
+			ArtifactType: manifest.ArtifactType,
+			Digest:       m.algorithm.FromBytes(raw),
+			Size:         int64(len(raw)),
+		},
+	}, nil
+}
+
+// describe computes the descriptor of the content read from r, recording
+// any error in m.
+func (m *Manifest) describe(mediaType string, r io.Reader) (v1.Descriptor, bool) {
+	if m.err != nil {
+		return v1.Descriptor{}, false
+	}
+	desc, err := Describe(m.algorithm, mediaType, r, m.inlineLimit)
+	if err != nil {
+		m.err = err
+		return v1.Descriptor{}, false
+	}
+	return desc, true
+}
+
+// Describe computes the descriptor of the content read from r, until EOF,
+// with the given digest algorithm. The content is embedded in the data
+// field of the descriptor when its size is at most inlineLimit bytes; a
+// negative inlineLimit disables embedding.
+func Describe(alg digest.Algorithm, mediaType string, r io.Reader, inlineLimit int64) (v1.Descriptor, error) {
+	if !alg.Available() {
+		return v1.Descriptor{}, fmt.Errorf("digest algorithm %q is not available", alg)
+	}
+	digester := alg.Digester()
+	w := io.Writer(digester.Hash())
+	var data *limitedBuffer
+	if inlineLimit >= 0 {
+		data = &limitedBuffer{limit: inlineLimit}
+		w = io.MultiWriter(w, data)
+	}
+	size, err := io.Copy(w, r)
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("reading %s content: %w", mediaType, err)
+	}
+
+	desc := v1.Descriptor{
+		MediaType: mediaType,
+		Digest:    digester.Digest(),
+		Size:      size,
+	}
+	if data != nil && !data.overflow {
+		desc.Data = data.buf.Bytes()
+	}
+	return desc, nil
+}
+
+// limitedBuffer retains the first limit bytes written to it, and records
+// whether more were written.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int64
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if !b.overflow {
+		if int64(b.buf.Len()+len(p)) > b.limit {
+			b.overflow = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
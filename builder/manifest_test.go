@@ -0,0 +1,195 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"bytes"
+	_ "crypto/sha256" // required to install sha256 digest support
+	_ "crypto/sha512" // required to install sha512 digest support
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type validatorFunc func(io.Reader) error
+
+func (f validatorFunc) Validate(r io.Reader) error { return f(r) }
+
+func TestBuildImage(t *testing.T) {
+	config := []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`)
+	layer := []byte("layer content")
+	subject := v1.Descriptor{
+		MediaType: v1.MediaTypeImageManifest,
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+
+	var validated []byte
+	res, err := NewManifest().
+		Config(v1.MediaTypeImageConfig, config).
+		LayerFrom(v1.MediaTypeImageLayer, bytes.NewReader(layer)).
+		Subject(subject).
+		Annotation(v1.AnnotationTitle, "example").
+		Validate(validatorFunc(func(r io.Reader) (err error) {
+			validated, err = io.ReadAll(r)
+			return err
+		})).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(validated, res.Bytes) {
+		t.Errorf("validator got %q, expected %q", validated, res.Bytes)
+	}
+
+	expected := v1.Descriptor{
+		MediaType: v1.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(res.Bytes),
+		Size:      int64(len(res.Bytes)),
+	}
+	if !reflect.DeepEqual(res.Descriptor, expected) {
+		t.Errorf("unexpected descriptor: %+v", res.Descriptor)
+	}
+
+	var manifest v1.Manifest
+	if err := json.Unmarshal(res.Bytes, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.SchemaVersion != 2 || manifest.MediaType != v1.MediaTypeImageManifest {
+		t.Errorf("unexpected header: %d, %q", manifest.SchemaVersion, manifest.MediaType)
+	}
+	if expected := (v1.Descriptor{
+		MediaType: v1.MediaTypeImageConfig,
+		Digest:    digest.FromBytes(config),
+		Size:      int64(len(config)),
+	}); !reflect.DeepEqual(manifest.Config, expected) {
+		t.Errorf("unexpected config: %+v", manifest.Config)
+	}
+	if expected := []v1.Descriptor{{
+		MediaType: v1.MediaTypeImageLayer,
+		Digest:    digest.FromBytes(layer),
+		Size:      int64(len(layer)),
+	}}; !reflect.DeepEqual(manifest.Layers, expected) {
+		t.Errorf("unexpected layers: %+v", manifest.Layers)
+	}
+	if manifest.Subject == nil || !reflect.DeepEqual(*manifest.Subject, subject) {
+		t.Errorf("unexpected subject: %+v", manifest.Subject)
+	}
+	if manifest.Annotations[v1.AnnotationTitle] != "example" {
+		t.Errorf("unexpected annotations: %v", manifest.Annotations)
+	}
+}
+
+func TestBuildArtifact(t *testing.T) {
+	res, err := NewManifest().Validate(NoValidation).
+		Algorithm(digest.SHA512).
+		Inline(4).
+		ArtifactType("application/vnd.example+type").
+		Layer("text/plain", []byte("abc")).
+		Layer("text/plain", []byte("abcde")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Descriptor.ArtifactType != "application/vnd.example+type" {
+		t.Errorf("unexpected artifact type: %q", res.Descriptor.ArtifactType)
+	}
+	if res.Descriptor.Digest != digest.SHA512.FromBytes(res.Bytes) {
+		t.Errorf("unexpected digest: %s", res.Descriptor.Digest)
+	}
+
+	var manifest v1.Manifest
+	if err := json.Unmarshal(res.Bytes, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(manifest.Config, v1.DescriptorEmptyJSON) {
+		t.Errorf("unexpected config: %+v", manifest.Config)
+	}
+	if len(manifest.Layers) != 2 {
+		t.Fatalf("unexpected layers: %+v", manifest.Layers)
+	}
+	if d := manifest.Layers[0]; d.Digest != digest.SHA512.FromString("abc") || string(d.Data) != "abc" {
+		t.Errorf("unexpected inlined layer: %+v", d)
+	}
+	if d := manifest.Layers[1]; d.Digest != digest.SHA512.FromString("abcde") || d.Data != nil {
+		t.Errorf("unexpected layer: %+v", d)
+	}
+
+	res, err = NewManifest().Validate(NoValidation).ArtifactType("application/vnd.example+type").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(res.Bytes), `"layers":[]`) {
+		t.Errorf("expected empty layers: %s", res.Bytes)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	errRead := errors.New("read failure")
+	errInvalid := errors.New("invalid")
+	for _, tt := range []struct {
+		name    string
+		builder *Manifest
+		err     error
+	}{
+		{
+			name:    "no config",
+			builder: NewManifest().Validate(NoValidation).Layer(v1.MediaTypeImageLayer, nil),
+		},
+		{
+			name:    "unavailable algorithm",
+			builder: NewManifest().Validate(NoValidation).Algorithm("unknown").Config(v1.MediaTypeImageConfig, nil),
+		},
+		{
+			name: "read failure",
+			builder: NewManifest().Validate(NoValidation).
+				Config(v1.MediaTypeImageConfig, nil).
+				LayerFrom(v1.MediaTypeImageLayer, io.MultiReader(strings.NewReader("abc"), &failingReader{errRead})),
+			err: errRead,
+		},
+		{
+			name: "validation",
+			builder: NewManifest().
+				Config(v1.MediaTypeImageConfig, nil).
+				Validate(validatorFunc(func(io.Reader) error { return errInvalid })),
+			err: errInvalid,
+		},
+		{
+			name:    "no validator",
+			builder: NewManifest().Config(v1.MediaTypeImageConfig, nil),
+			err:     ErrNoValidator,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+type failingReader struct{ err error }
+
+func (r *failingReader) Read([]byte) (int, error) { return 0, r.err }
@@ -0,0 +1,179 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compression provides the compression algorithms of layers and
+// other blobs, identified as in media type suffixes.
+//
+// None, Gzip and Zstd are always available, Zstd being implemented by
+// github.com/klauspost/compress/zstd. Other algorithms, or other
+// implementations, can be registered with Register.
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Algorithm identifies a compression algorithm.
+type Algorithm string
+
+// Supported compression algorithms.
+const (
+	// None is the identity, for uncompressed content.
+	None Algorithm = ""
+
+	// Gzip is the gzip format of RFC 1952.
+	Gzip Algorithm = "gzip"
+
+	// Zstd is the Zstandard format of RFC 8878.
+	Zstd Algorithm = "zstd"
+)
+
+// ErrUnavailable is returned for algorithms without a registered
+// implementation.
+var ErrUnavailable = errors.New("compression algorithm not available")
+
+// Compressor returns a writer compressing to w. Closing it flushes the
+// compressed stream, but does not close w.
+type Compressor func(w io.Writer) (io.WriteCloser, error)
+
+// Decompressor returns a reader decompressing r.
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+type implementation struct {
+	compressor   Compressor
+	decompressor Decompressor
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[Algorithm]implementation{
+		None: {
+			compressor:   func(w io.Writer) (io.WriteCloser, error) { return nopWriteCloser{w}, nil },
+			decompressor: func(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(r), nil },
+		},
+		Gzip: {
+			compressor: func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil },
+			decompressor: func(r io.Reader) (io.ReadCloser, error) {
+				return gzip.NewReader(r)
+			},
+		},
+		Zstd: {
+			compressor: func(w io.Writer) (io.WriteCloser, error) { return zstd.NewWriter(w) },
+			decompressor: func(r io.Reader) (io.ReadCloser, error) {
+				// a single goroutine, as content is read as a stream
+				d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+				if err != nil {
+					return nil, err
+				}
+				return d.IOReadCloser(), nil
+			},
+		},
+	}
+)
+
+// Register registers the implementation of an algorithm, replacing any
+// previous one. It is typically called from an init function.
+func Register(alg Algorithm, c Compressor, d Decompressor) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[alg] = implementation{c, d}
+}
+
+func (a Algorithm) implementation() (implementation, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	impl, ok := registry[a]
+	if !ok {
+		return implementation{}, fmt.Errorf("%w: %s", ErrUnavailable, a)
+	}
+	return impl, nil
+}
+
+// Available returns whether the algorithm has a registered implementation.
+func (a Algorithm) Available() bool {
+	_, err := a.implementation()
+	return err == nil
+}
+
+// Compress returns a writer compressing to w with the algorithm.
+func (a Algorithm) Compress(w io.Writer) (io.WriteCloser, error) {
+	impl, err := a.implementation()
+	if err != nil {
+		return nil, err
+	}
+	return impl.compressor(w)
+}
+
+// Decompress returns a reader decompressing r with the algorithm.
+func (a Algorithm) Decompress(r io.Reader) (io.ReadCloser, error) {
+	impl, err := a.implementation()
+	if err != nil {
+		return nil, err
+	}
+	return impl.decompressor(r)
+}
+
+// String returns the name of the algorithm, or "none" for None.
+func (a Algorithm) String() string {
+	if a == None {
+		return "none"
+	}
+	return string(a)
+}
+
+// FromMediaType returns the compression algorithm of a media type, given by
+// its `+gzip` or `+zstd` suffix, such as in v1.MediaTypeImageLayerGzip, or
+// its `.gzip` or `.zstd` extension as in the Docker layer media types.
+// Media types without such a suffix are uncompressed.
+func FromMediaType(mediaType string) Algorithm {
+	for _, alg := range []Algorithm{Gzip, Zstd} {
+		if strings.HasSuffix(mediaType, "+"+string(alg)) || strings.HasSuffix(mediaType, "."+string(alg)) {
+			return alg
+		}
+	}
+	return None
+}
+
+// magics are the magic numbers starting the compressed streams.
+var magics = []struct {
+	alg   Algorithm
+	magic []byte
+}{
+	{Gzip, []byte{0x1f, 0x8b}},
+	{Zstd, []byte{0x28, 0xb5, 0x2f, 0xfd}},
+}
+
+// Detect returns the compression algorithm of a stream starting with header,
+// from its magic number, or None if it is not compressed with a known
+// algorithm. Four bytes of header are enough to detect every algorithm.
+func Detect(header []byte) Algorithm {
+	for _, m := range magics {
+		if bytes.HasPrefix(header, m.magic) {
+			return m.alg
+		}
+	}
+	return None
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
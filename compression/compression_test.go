@@ -0,0 +1,138 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestFromMediaType(t *testing.T) {
+	for _, tt := range []struct {
+		mediaType string
+		expected  Algorithm
+	}{
+		{v1.MediaTypeImageLayer, None},
+		{v1.MediaTypeImageLayerGzip, Gzip},
+		{v1.MediaTypeImageLayerZstd, Zstd},
+		{"application/vnd.docker.image.rootfs.diff.tar.gzip", Gzip},
+		{"application/vnd.oci.image.layer.nondistributable.v1.tar+gzip", Gzip},
+		{"application/octet-stream", None},
+	} {
+		if alg := FromMediaType(tt.mediaType); alg != tt.expected {
+			t.Errorf("unexpected algorithm for %s: %s", tt.mediaType, alg)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	for _, tt := range []struct {
+		header   []byte
+		expected Algorithm
+	}{
+		{header: []byte{0x1f, 0x8b, 0x08, 0x00}, expected: Gzip},
+		{header: []byte{0x28, 0xb5, 0x2f, 0xfd, 0x04}, expected: Zstd},
+		{header: []byte("oci-layout"), expected: None},
+		{header: []byte{0x1f}, expected: None},
+		{header: nil, expected: None},
+	} {
+		if alg := Detect(tt.header); alg != tt.expected {
+			t.Errorf("unexpected algorithm for %x: %s", tt.header, alg)
+		}
+	}
+}
+
+func roundTrip(t *testing.T, alg Algorithm, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := alg.Compress(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := alg.Decompress(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("layer content "), 100)
+	for _, alg := range []Algorithm{None, Gzip, Zstd} {
+		if out := roundTrip(t, alg, data); !bytes.Equal(out, data) {
+			t.Errorf("%s: unexpected content after round trip", alg)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	const fake Algorithm = "reverse"
+	if fake.Available() {
+		t.Fatal("unexpected available algorithm")
+	}
+	if _, err := fake.Decompress(bytes.NewReader(nil)); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reverse := func(b []byte) []byte {
+		out := make([]byte, len(b))
+		for i := range b {
+			out[len(b)-1-i] = b[i]
+		}
+		return out
+	}
+	Register(fake,
+		func(w io.Writer) (io.WriteCloser, error) {
+			return &reverseWriter{w: w, reverse: reverse}, nil
+		},
+		func(r io.Reader) (io.ReadCloser, error) {
+			b, err := io.ReadAll(r)
+			return io.NopCloser(bytes.NewReader(reverse(b))), err
+		})
+	if !fake.Available() {
+		t.Fatal("registered algorithm is not available")
+	}
+	if out := roundTrip(t, fake, []byte("abc")); string(out) != "abc" {
+		t.Errorf("unexpected content after round trip: %q", out)
+	}
+}
+
+type reverseWriter struct {
+	w       io.Writer
+	buf     bytes.Buffer
+	reverse func([]byte) []byte
+}
+
+func (w *reverseWriter) Write(p []byte) (int, error) { return w.buf.Write(p) }
+
+func (w *reverseWriter) Close() error {
+	_, err := w.w.Write(w.reverse(w.buf.Bytes()))
+	return err
+}
@@ -0,0 +1,190 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestEnv(t *testing.T) {
+	img := v1.Image{Config: v1.ImageConfig{Env: []string{
+		"PATH=/usr/bin:/bin",
+		"FOO=first",
+		"BAR=bar",
+		"FOO=second",
+	}}}
+	env := Env(img.Config.Env)
+	if err := env.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := env.Lookup("FOO"); !ok || v != "second" {
+		t.Errorf("unexpected FOO: %q, %t", v, ok)
+	}
+	if _, ok := env.Lookup("MISSING"); ok {
+		t.Error("unexpected MISSING")
+	}
+
+	merged := env.Merge([]string{"FOO=third", "BAR", "NEW=1", "EMPTY="})
+	expected := Env{"PATH=/usr/bin:/bin", "FOO=first", "FOO=third", "NEW=1", "EMPTY="}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("unexpected merge result: %q", merged)
+	}
+	if !reflect.DeepEqual([]string(env), img.Config.Env) || len(env) != 4 {
+		t.Errorf("merge modified its receiver: %q", env)
+	}
+
+	env = append(Env{}, env...)
+	env.Set("PATH", "/bin")
+	env.Unset("FOO")
+	img.Config.Env = env
+	if expected := []string{"PATH=/bin", "BAR=bar"}; !reflect.DeepEqual(img.Config.Env, expected) {
+		t.Errorf("unexpected env: %q", img.Config.Env)
+	}
+
+	for _, invalid := range []string{"FOO", "=bar", ""} {
+		if err := (Env{invalid}).Validate(); err == nil {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestParsePort(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected Port
+		str      string
+		fail     bool
+	}{
+		{input: "80", expected: Port{80, 80, ProtocolTCP}, str: "80/tcp"},
+		{input: "80/tcp", expected: Port{80, 80, ProtocolTCP}},
+		{input: "53/udp", expected: Port{53, 53, ProtocolUDP}},
+		{input: "9000/sctp", expected: Port{9000, 9000, ProtocolSCTP}},
+		{input: "8000-8100", expected: Port{8000, 8100, ProtocolTCP}, str: "8000-8100/tcp"},
+		{input: "60000-60010/udp", expected: Port{60000, 60010, ProtocolUDP}},
+		{input: "", fail: true},
+		{input: "0", fail: true},
+		{input: "65536", fail: true},
+		{input: "80/icmp", fail: true},
+		{input: "80/", fail: true},
+		{input: "http", fail: true},
+		{input: "8100-8000", fail: true},
+		{input: "8000-", fail: true},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			p, err := ParsePort(tt.input)
+			if tt.fail {
+				if err == nil {
+					t.Fatalf("expected error, got %v", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p != tt.expected {
+				t.Errorf("unexpected port: %+v != %+v", p, tt.expected)
+			}
+			str := tt.str
+			if str == "" {
+				str = tt.input
+			}
+			if p.String() != str {
+				t.Errorf("unexpected string form: %q != %q", p.String(), str)
+			}
+		})
+	}
+}
+
+func TestExposedPorts(t *testing.T) {
+	config := v1.ImageConfig{ExposedPorts: map[string]struct{}{
+		"8080/tcp": {},
+		"53/udp":   {},
+		"443":      {},
+	}}
+	ports, err := ParseExposedPorts(config.ExposedPorts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Port{{443, 443, ProtocolTCP}, {8080, 8080, ProtocolTCP}, {53, 53, ProtocolUDP}}
+	if !reflect.DeepEqual(ports, expected) {
+		t.Errorf("unexpected ports: %v", ports)
+	}
+
+	config.ExposedPorts = ExposedPorts(ports)
+	if expected := map[string]struct{}{"443/tcp": {}, "8080/tcp": {}, "53/udp": {}}; !reflect.DeepEqual(config.ExposedPorts, expected) {
+		t.Errorf("unexpected exposed ports: %v", config.ExposedPorts)
+	}
+
+	if _, err := ParseExposedPorts(map[string]struct{}{"80/tcp": {}, "bogus": {}}); err == nil {
+		t.Error("expected invalid port to fail")
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected Signal
+		str      string
+		fail     bool
+	}{
+		{input: "SIGTERM", expected: 15},
+		{input: "TERM", expected: 15, str: "SIGTERM"},
+		{input: "sigkill", expected: 9, str: "SIGKILL"},
+		{input: "15", expected: 15, str: "SIGTERM"},
+		{input: "SIGIOT", expected: 6, str: "SIGABRT"},
+		{input: "SIGRTMIN", expected: 34},
+		{input: "SIGRTMIN+3", expected: 37},
+		{input: "RTMIN+30", expected: 64, str: "SIGRTMAX"},
+		{input: "SIGRTMAX-1", expected: 63, str: "SIGRTMIN+29"},
+		{input: "40", expected: 40, str: "SIGRTMIN+6"},
+		{input: "SIGFOO", fail: true},
+		{input: "0", fail: true},
+		{input: "32", fail: true},
+		{input: "65", fail: true},
+		{input: "SIGRTMIN+31", fail: true},
+		{input: "SIGRTMIN-1", fail: true},
+		{input: "SIGRTMAX+1", fail: true},
+		{input: "SIGRTMAX-40", fail: true},
+		{input: "SIGRTMIN+", fail: true},
+		{input: "", fail: true},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			sig, err := ParseSignal(tt.input)
+			if tt.fail {
+				if err == nil {
+					t.Fatalf("expected error, got %v", sig)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sig != tt.expected {
+				t.Errorf("unexpected signal: %d != %d", sig, tt.expected)
+			}
+			str := tt.str
+			if str == "" {
+				str = tt.input
+			}
+			if sig.String() != str {
+				t.Errorf("unexpected string form: %q != %q", sig.String(), str)
+			}
+		})
+	}
+}
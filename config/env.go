@@ -0,0 +1,107 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config provides typed models for the execution parameters of
+// v1.ImageConfig that are stored as plain strings: environment variables,
+// exposed ports and the stop signal. Each model converts back to the
+// representation used by the corresponding ImageConfig field.
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Env is an ordered list of environment variables in the `VARNAME=VARVALUE`
+// format of ImageConfig.Env. Converting between Env and []string is lossless.
+//
+// Duplicate variable names are preserved, as container runtimes pass the
+// list to the process unchanged. Lookup and Set operate on the last entry of
+// a name, which is the one that takes effect when the list is applied in
+// order.
+type Env []string
+
+// Validate checks that every entry has a non-empty variable name followed
+// by "=".
+func (e Env) Validate() error {
+	for _, entry := range e {
+		name, _, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("unexpected env: %q", entry)
+		}
+	}
+	return nil
+}
+
+// Lookup returns the value of the last entry named name.
+func (e Env) Lookup(name string) (string, bool) {
+	if i := e.index(name); i >= 0 {
+		_, value, _ := strings.Cut(e[i], "=")
+		return value, true
+	}
+	return "", false
+}
+
+// Set replaces the value of the last entry named name, or appends a new
+// entry if there is none.
+func (e *Env) Set(name, value string) {
+	entry := name + "=" + value
+	if i := e.index(name); i >= 0 {
+		(*e)[i] = entry
+		return
+	}
+	*e = append(*e, entry)
+}
+
+// Unset removes every entry named name.
+func (e *Env) Unset(name string) {
+	kept := (*e)[:0]
+	for _, entry := range *e {
+		if entryName(entry) != name {
+			kept = append(kept, entry)
+		}
+	}
+	*e = kept
+}
+
+// Merge applies overrides in order: a `VARNAME=VARVALUE` entry is applied as
+// with Set, and a bare `VARNAME` entry is applied as with Unset. Entries of e
+// that are not overridden keep their position, so the result is e followed
+// by the variables only present in overrides.
+func (e Env) Merge(overrides []string) Env {
+	merged := append(Env{}, e...)
+	for _, o := range overrides {
+		name, value, ok := strings.Cut(o, "=")
+		if !ok {
+			merged.Unset(name)
+			continue
+		}
+		merged.Set(name, value)
+	}
+	return merged
+}
+
+func (e Env) index(name string) int {
+	for i := len(e) - 1; i >= 0; i-- {
+		if entryName(e[i]) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func entryName(entry string) string {
+	name, _, _ := strings.Cut(entry, "=")
+	return name
+}
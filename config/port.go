@@ -0,0 +1,123 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Protocols for exposed ports.
+const (
+	ProtocolTCP  = "tcp"
+	ProtocolUDP  = "udp"
+	ProtocolSCTP = "sctp"
+)
+
+// Port is an exposed port, or range of ports, of ImageConfig.ExposedPorts.
+type Port struct {
+	// Start is the first port of the range.
+	Start uint16
+
+	// End is the last port of the range, equal to Start for a single port.
+	End uint16
+
+	// Protocol is one of ProtocolTCP, ProtocolUDP or ProtocolSCTP.
+	Protocol string
+}
+
+// ParsePort parses an ExposedPorts key in the format `port`, `port/proto`,
+// `start-end` or `start-end/proto`. The protocol defaults to tcp.
+func ParsePort(s string) (Port, error) {
+	ports, proto, hasProto := strings.Cut(s, "/")
+	p := Port{Protocol: ProtocolTCP}
+	if hasProto {
+		switch proto {
+		case ProtocolTCP, ProtocolUDP, ProtocolSCTP:
+			p.Protocol = proto
+		default:
+			return Port{}, fmt.Errorf("invalid port %q: unsupported protocol %q", s, proto)
+		}
+	}
+
+	start, end, isRange := strings.Cut(ports, "-")
+	var err error
+	if p.Start, err = parsePortNumber(start); err != nil {
+		return Port{}, fmt.Errorf("invalid port %q: %w", s, err)
+	}
+	p.End = p.Start
+	if isRange {
+		if p.End, err = parsePortNumber(end); err != nil {
+			return Port{}, fmt.Errorf("invalid port %q: %w", s, err)
+		}
+		if p.End < p.Start {
+			return Port{}, fmt.Errorf("invalid port %q: range end is lower than its start", s)
+		}
+	}
+	return p, nil
+}
+
+func parsePortNumber(s string) (uint16, error) {
+	n, err := strconv.ParseUint(s, 10, 16)
+	if err != nil || n == 0 {
+		return 0, fmt.Errorf("port number %q is not in the range 1-65535", s)
+	}
+	return uint16(n), nil
+}
+
+// String returns the port in the `port/proto` or `start-end/proto` format.
+func (p Port) String() string {
+	if p.Start == p.End {
+		return fmt.Sprintf("%d/%s", p.Start, p.Protocol)
+	}
+	return fmt.Sprintf("%d-%d/%s", p.Start, p.End, p.Protocol)
+}
+
+// ParseExposedPorts parses the keys of ImageConfig.ExposedPorts, returning
+// them ordered by protocol and port.
+func ParseExposedPorts(m map[string]struct{}) ([]Port, error) {
+	ports := make([]Port, 0, len(m))
+	for k := range m {
+		p, err := ParsePort(k)
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, p)
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Protocol != ports[j].Protocol {
+			return ports[i].Protocol < ports[j].Protocol
+		}
+		if ports[i].Start != ports[j].Start {
+			return ports[i].Start < ports[j].Start
+		}
+		return ports[i].End < ports[j].End
+	})
+	return ports, nil
+}
+
+// ExposedPorts returns ports in the representation of ImageConfig.ExposedPorts.
+func ExposedPorts(ports []Port) map[string]struct{} {
+	if len(ports) == 0 {
+		return nil
+	}
+	m := make(map[string]struct{}, len(ports))
+	for _, p := range ports {
+		m[p.String()] = struct{}{}
+	}
+	return m
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Signal is a signal number for ImageConfig.StopSignal.
+//
+// Signal numbers are platform specific. Names are resolved with the Linux
+// numbering shared by most architectures (including amd64, arm64, ppc64le,
+// riscv64 and s390x), independently of the platform the code runs on, with
+// real-time signals in the range SIGRTMIN (34) to SIGRTMAX (64) as exposed by
+// the C library.
+type Signal int
+
+// Real-time signal bounds.
+const (
+	SIGRTMIN Signal = 34
+	SIGRTMAX Signal = 64
+)
+
+var signalNames = []string{
+	1:  "HUP",
+	2:  "INT",
+	3:  "QUIT",
+	4:  "ILL",
+	5:  "TRAP",
+	6:  "ABRT",
+	7:  "BUS",
+	8:  "FPE",
+	9:  "KILL",
+	10: "USR1",
+	11: "SEGV",
+	12: "USR2",
+	13: "PIPE",
+	14: "ALRM",
+	15: "TERM",
+	16: "STKFLT",
+	17: "CHLD",
+	18: "CONT",
+	19: "STOP",
+	20: "TSTP",
+	21: "TTIN",
+	22: "TTOU",
+	23: "URG",
+	24: "XCPU",
+	25: "XFSZ",
+	26: "VTALRM",
+	27: "PROF",
+	28: "WINCH",
+	29: "IO",
+	30: "PWR",
+	31: "SYS",
+}
+
+// signalAliases are alternative names for signals of signalNames.
+var signalAliases = map[string]Signal{
+	"IOT":  6,
+	"CLD":  17,
+	"POLL": 29,
+}
+
+// ParseSignal parses a signal given by name, with or without the `SIG`
+// prefix and in any case (`SIGTERM`, `term`), by number (`15`), or as an
+// offset from the real-time signal bounds (`SIGRTMIN+3`, `RTMAX-1`).
+func ParseSignal(s string) (Signal, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		sig := Signal(n)
+		if !sig.valid() {
+			return 0, fmt.Errorf("invalid signal %q: out of range", s)
+		}
+		return sig, nil
+	}
+
+	name := strings.TrimPrefix(strings.ToUpper(s), "SIG")
+	for i, n := range signalNames {
+		if n != "" && n == name {
+			return Signal(i), nil
+		}
+	}
+	if sig, ok := signalAliases[name]; ok {
+		return sig, nil
+	}
+	if sig, ok := parseRealtime(name); ok {
+		if sig < SIGRTMIN || sig > SIGRTMAX {
+			return 0, fmt.Errorf("invalid signal %q: out of range", s)
+		}
+		return sig, nil
+	}
+	return 0, fmt.Errorf("invalid signal %q", s)
+}
+
+// parseRealtime parses `RTMIN`, `RTMIN+n`, `RTMAX` and `RTMAX-n`.
+func parseRealtime(name string) (Signal, bool) {
+	var (
+		base   Signal
+		offset string
+		sign   Signal
+	)
+	switch {
+	case strings.HasPrefix(name, "RTMIN"):
+		base, offset, sign = SIGRTMIN, strings.TrimPrefix(name, "RTMIN"), 1
+		if offset != "" && offset[0] != '+' {
+			return 0, false
+		}
+	case strings.HasPrefix(name, "RTMAX"):
+		base, offset, sign = SIGRTMAX, strings.TrimPrefix(name, "RTMAX"), -1
+		if offset != "" && offset[0] != '-' {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	if offset == "" {
+		return base, true
+	}
+	n, err := strconv.Atoi(offset[1:])
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return base + sign*Signal(n), true
+}
+
+func (s Signal) valid() bool {
+	return s > 0 && int(s) < len(signalNames) || s >= SIGRTMIN && s <= SIGRTMAX
+}
+
+// String returns the `SIGNAME` form of the signal used by
+// ImageConfig.StopSignal, such as `SIGTERM` or `SIGRTMIN+3`. Numbers that
+// are not signals are formatted as decimals.
+func (s Signal) String() string {
+	switch {
+	case s > 0 && int(s) < len(signalNames):
+		return "SIG" + signalNames[s]
+	case s == SIGRTMIN:
+		return "SIGRTMIN"
+	case s == SIGRTMAX:
+		return "SIGRTMAX"
+	case s > SIGRTMIN && s < SIGRTMAX:
+		return fmt.Sprintf("SIGRTMIN+%d", s-SIGRTMIN)
+	}
+	return strconv.Itoa(int(s))
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package content defines the interfaces of stores holding content
+// addressed by descriptors, such as image layouts and registries.
+package content
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ErrNotFound is returned when the content of a descriptor, or the target of
+// a reference, is not in a store.
+var ErrNotFound = errors.New("content not found")
+
+// Fetcher fetches content.
+type Fetcher interface {
+	// Fetch returns a reader for the content of desc. The reader is not
+	// required to verify the content against desc.
+	Fetch(ctx context.Context, desc v1.Descriptor) (io.ReadCloser, error)
+}
+
+// Exister checks whether content exists.
+type Exister interface {
+	// Exists returns whether the content of desc is in the store. The
+	// content is not required to be verified.
+	Exists(ctx context.Context, desc v1.Descriptor) (bool, error)
+}
+
+// Pusher pushes content.
+type Pusher interface {
+	// Push stores the content read from r, which must match the digest and
+	// size of expected. Pushing content that is already present is not an
+	// error.
+	Push(ctx context.Context, expected v1.Descriptor, r io.Reader) error
+}
+
+// Resolver resolves references, such as tags, to descriptors.
+type Resolver interface {
+	// Resolve returns the descriptor reference points to, or an error
+	// wrapping ErrNotFound if there is none.
+	Resolve(ctx context.Context, reference string) (v1.Descriptor, error)
+}
+
+// Tagger tags content.
+type Tagger interface {
+	// Tag points reference to desc, replacing any previous target.
+	Tag(ctx context.Context, desc v1.Descriptor, reference string) error
+}
+
+// TagLister lists tags.
+type TagLister interface {
+	// Tags returns the references of the store, sorted.
+	Tags(ctx context.Context) ([]string, error)
+}
+
+// Store is a store holding content and references to it, such as an image
+// layout.
+type Store interface {
+	Fetcher
+	Exister
+	Pusher
+	Resolver
+	Tagger
+	TagLister
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package content
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Copy fetches the content of desc from f and writes it to w, verifying its
+// size and digest. On error, w may have received partial or unverified
+// content.
+func Copy(ctx context.Context, w io.Writer, f Fetcher, desc v1.Descriptor) error {
+	if err := desc.Digest.Validate(); err != nil {
+		return err
+	}
+	if desc.Size < 0 {
+		return fmt.Errorf("content %s has invalid size %d", desc.Digest, desc.Size)
+	}
+	rc, err := f.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	verifier := desc.Digest.Verifier()
+	n, err := io.Copy(io.MultiWriter(w, verifier), io.LimitReader(rc, desc.Size+1))
+	if err != nil {
+		return err
+	}
+	if n != desc.Size {
+		return fmt.Errorf("content %s has unexpected size", desc.Digest)
+	}
+	if !verifier.Verified() {
+		return fmt.Errorf("content %s does not match its digest", desc.Digest)
+	}
+	return nil
+}
+
+// ReadAll fetches the content of desc from f and returns it once verified.
+// Descriptors larger than limit bytes are rejected without fetching them.
+func ReadAll(ctx context.Context, f Fetcher, desc v1.Descriptor, limit int64) ([]byte, error) {
+	if desc.Size < 0 {
+		return nil, fmt.Errorf("content %s has invalid size %d", desc.Digest, desc.Size)
+	}
+	if desc.Size > limit {
+		return nil, fmt.Errorf("content %s is too large: %d bytes", desc.Digest, desc.Size)
+	}
+	var buf bytes.Buffer
+	buf.Grow(int(desc.Size))
+	if err := Copy(ctx, &buf, f, desc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
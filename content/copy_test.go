@@ -0,0 +1,84 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package content
+
+import (
+	"context"
+	_ "crypto/sha256" // required to install sha256 digest support
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type stringFetcher string
+
+func (s stringFetcher) Fetch(context.Context, v1.Descriptor) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(string(s))), nil
+}
+
+func TestReadAll(t *testing.T) {
+	desc := v1.Descriptor{Digest: digest.FromString("content"), Size: 7}
+	for _, tt := range []struct {
+		name    string
+		content string
+		desc    v1.Descriptor
+		limit   int64
+		fail    bool
+	}{
+		{name: "valid", content: "content", desc: desc, limit: 7},
+		{name: "truncated", content: "conten", desc: desc, limit: 7, fail: true},
+		{name: "extended", content: "content!", desc: desc, limit: 7, fail: true},
+		{name: "corrupt", content: "CONTENT", desc: desc, limit: 7, fail: true},
+		{name: "too large", content: "content", desc: desc, limit: 6, fail: true},
+		{name: "negative size", content: "content", desc: v1.Descriptor{Digest: desc.Digest, Size: -1}, limit: 7, fail: true},
+		{name: "invalid digest", content: "content", desc: v1.Descriptor{Digest: "sha256:abc", Size: 7}, limit: 7, fail: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ReadAll(context.Background(), stringFetcher(tt.content), tt.desc, tt.limit)
+			if tt.fail {
+				if err == nil {
+					t.Errorf("expected error, got %q", b)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.content {
+				t.Errorf("unexpected content: %q", b)
+			}
+		})
+	}
+}
+
+type failingFetcher struct{ t *testing.T }
+
+func (f failingFetcher) Fetch(_ context.Context, desc v1.Descriptor) (io.ReadCloser, error) {
+	f.t.Errorf("unexpected fetch of %s", desc.Digest)
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func TestCopyNegativeSize(t *testing.T) {
+	desc := v1.Descriptor{Digest: digest.FromString(""), Size: -1}
+	if err := Copy(context.Background(), io.Discard, failingFetcher{t}, desc); err == nil {
+		t.Error("expected error for a negative size")
+	}
+	if _, err := ReadAll(context.Background(), failingFetcher{t}, desc, 7); err == nil {
+		t.Error("expected error for a negative size")
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package content
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Memory is a Store keeping content in memory, typically for tests.
+type Memory struct {
+	mu    sync.RWMutex
+	blobs map[digest.Digest][]byte
+	tags  map[string]v1.Descriptor
+}
+
+var _ Store = (*Memory)(nil)
+
+// NewMemory returns an empty Memory store.
+func NewMemory() *Memory {
+	return &Memory{
+		blobs: map[digest.Digest][]byte{},
+		tags:  map[string]v1.Descriptor{},
+	}
+}
+
+// Fetch returns a reader for the content of desc.
+func (m *Memory) Fetch(_ context.Context, desc v1.Descriptor) (io.ReadCloser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	blob, ok := m.blobs[desc.Digest]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, desc.Digest)
+	}
+	return io.NopCloser(bytes.NewReader(blob)), nil
+}
+
+// Exists returns whether the content of desc is in the store, with the size
+// of desc.
+func (m *Memory) Exists(_ context.Context, desc v1.Descriptor) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	blob, ok := m.blobs[desc.Digest]
+	return ok && int64(len(blob)) == desc.Size, nil
+}
+
+// Push stores the content read from r, once verified against expected.
+func (m *Memory) Push(_ context.Context, expected v1.Descriptor, r io.Reader) error {
+	if err := expected.Digest.Validate(); err != nil {
+		return err
+	}
+	blob, err := io.ReadAll(io.LimitReader(r, expected.Size+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(blob)) != expected.Size {
+		return fmt.Errorf("content %s has unexpected size", expected.Digest)
+	}
+	if expected.Digest.Algorithm().FromBytes(blob) != expected.Digest {
+		return fmt.Errorf("content %s does not match its digest", expected.Digest)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blobs[expected.Digest] = blob
+	return nil
+}
+
+// Resolve returns the descriptor tagged with reference.
+func (m *Memory) Resolve(_ context.Context, reference string) (v1.Descriptor, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	desc, ok := m.tags[reference]
+	if !ok {
+		return v1.Descriptor{}, fmt.Errorf("%w: reference %q", ErrNotFound, reference)
+	}
+	return desc, nil
+}
+
+// Tag points reference to desc, whose content must be in the store.
+func (m *Memory) Tag(_ context.Context, desc v1.Descriptor, reference string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.blobs[desc.Digest]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, desc.Digest)
+	}
+	m.tags[reference] = desc
+	return nil
+}
+
+// Tags returns the references of the store, sorted.
+func (m *Memory) Tags(context.Context) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	tags := make([]string, 0, len(m.tags))
+	for tag := range m.tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
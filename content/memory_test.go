@@ -0,0 +1,76 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package content
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	desc := v1.Descriptor{MediaType: "text/plain", Digest: digest.FromString("content"), Size: 7}
+
+	if exists, err := m.Exists(ctx, desc); err != nil || exists {
+		t.Errorf("unexpected existence before push: %t, %v", exists, err)
+	}
+	if err := m.Tag(ctx, desc, "latest"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unexpected error tagging missing content: %v", err)
+	}
+	for _, invalid := range []string{"CONTENT", "conten", "content!"} {
+		if err := m.Push(ctx, desc, strings.NewReader(invalid)); err == nil {
+			t.Errorf("%q pushed for %s", invalid, desc.Digest)
+		}
+	}
+	if err := m.Push(ctx, desc, strings.NewReader("content")); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := m.Exists(ctx, desc); err != nil || !exists {
+		t.Errorf("unexpected existence after push: %t, %v", exists, err)
+	}
+
+	for _, tag := range []string{"v2", "v1", "latest"} {
+		if err := m.Tag(ctx, desc, tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tags, err := m.Tags(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(tags, ",") != "latest,v1,v2" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+	resolved, err := m.Resolve(ctx, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := ReadAll(ctx, m, resolved, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(blob) != "content" {
+		t.Errorf("unexpected content: %s", blob)
+	}
+	if _, err := m.Resolve(ctx, "v3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unexpected error resolving a missing tag: %v", err)
+	}
+}
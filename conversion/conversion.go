@@ -0,0 +1,195 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conversion converts an image configuration into an OCI runtime
+// configuration, as described in conversion.md.
+package conversion
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/image-spec/config"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Implicit annotations set on the runtime configuration, as defined in the
+// "Annotation Fields" and "Optional Fields" sections of conversion.md.
+const (
+	// AnnotationOS is the annotation key for the image `os`.
+	AnnotationOS = "org.opencontainers.image.os"
+
+	// AnnotationArchitecture is the annotation key for the image `architecture`.
+	AnnotationArchitecture = "org.opencontainers.image.architecture"
+
+	// AnnotationVariant is the annotation key for the image `variant`.
+	AnnotationVariant = "org.opencontainers.image.variant"
+
+	// AnnotationOSVersion is the annotation key for the image `os.version`.
+	AnnotationOSVersion = "org.opencontainers.image.os.version"
+
+	// AnnotationOSFeatures is the annotation key for the image `os.features` (comma-separated values).
+	AnnotationOSFeatures = "org.opencontainers.image.os.features"
+
+	// AnnotationAuthor is the annotation key for the image `author`.
+	AnnotationAuthor = "org.opencontainers.image.author"
+
+	// AnnotationStopSignal is the annotation key for `Config.StopSignal`.
+	AnnotationStopSignal = "org.opencontainers.image.stopSignal"
+
+	// AnnotationExposedPorts is the annotation key for the keys of `Config.ExposedPorts` (comma-separated values).
+	AnnotationExposedPorts = "org.opencontainers.image.exposedPorts"
+)
+
+// Options are externally provided inputs modifying the image configuration
+// used as a source for the conversion.
+type Options struct {
+	// Args, if not nil, replaces the process arguments derived from
+	// Config.Entrypoint and Config.Cmd.
+	Args []string
+
+	// Env entries are merged into Config.Env with config.Env.Merge: a
+	// `VARNAME=VARVALUE` entry replaces the value of VARNAME or adds it, and
+	// a bare `VARNAME` entry removes it.
+	Env []string
+
+	// User, if not empty, replaces Config.User.
+	User string
+
+	// RootFS, if not nil, is the root filesystem of the image, used to
+	// resolve user and group names with ResolveUser. Without it, only
+	// numeric users and groups can be converted.
+	RootFS fs.FS
+
+	// Volumes enables the generation of a mount for every Config.Volumes
+	// entry. Only the destination is set; the type, source and options are
+	// left for the caller to fill in.
+	Volumes bool
+}
+
+// Convert generates the runtime configuration for img, following the
+// verbatim, annotation, parsed and optional field rules of conversion.md.
+// The working directory is "/" when Config.WorkingDir is not set, and the
+// process runs as uid and gid 0 when no user is set.
+func Convert(img v1.Image, opts Options) (*Spec, error) {
+	spec := &Spec{
+		Process: Process{
+			Args: append(append([]string{}, img.Config.Entrypoint...), img.Config.Cmd...),
+			Env:  config.Env(img.Config.Env).Merge(opts.Env),
+			Cwd:  img.Config.WorkingDir,
+		},
+		Annotations: annotations(img),
+	}
+	if opts.Args != nil {
+		spec.Process.Args = append([]string{}, opts.Args...)
+	}
+	if len(spec.Process.Args) == 0 {
+		spec.Process.Args = nil
+	}
+	if len(spec.Process.Env) == 0 {
+		spec.Process.Env = nil
+	}
+	if spec.Process.Cwd == "" {
+		spec.Process.Cwd = "/"
+	}
+
+	user := img.Config.User
+	if opts.User != "" {
+		user = opts.User
+	}
+	if user != "" {
+		var (
+			u   User
+			err error
+		)
+		if opts.RootFS != nil {
+			u, err = ResolveUser(opts.RootFS, user)
+		} else {
+			u, err = parseUser(user)
+		}
+		if err != nil {
+			return nil, err
+		}
+		spec.Process.User = u
+	}
+
+	if opts.Volumes {
+		for _, volume := range sortedKeys(img.Config.Volumes) {
+			spec.Mounts = append(spec.Mounts, Mount{Destination: volume})
+		}
+	}
+
+	return spec, nil
+}
+
+// annotations returns the implicit annotations of img, overridden by any
+// explicitly specified value in Config.Labels.
+func annotations(img v1.Image) map[string]string {
+	m := map[string]string{}
+	set := func(key, value string) {
+		if value != "" {
+			m[key] = value
+		}
+	}
+	set(AnnotationOS, img.OS)
+	set(AnnotationArchitecture, img.Architecture)
+	set(AnnotationVariant, img.Variant)
+	set(AnnotationOSVersion, img.OSVersion)
+	set(AnnotationOSFeatures, strings.Join(img.OSFeatures, ","))
+	set(AnnotationAuthor, img.Author)
+	if img.Created != nil {
+		set(v1.AnnotationCreated, img.Created.Format(time.RFC3339Nano))
+	}
+	set(AnnotationStopSignal, img.Config.StopSignal)
+	set(AnnotationExposedPorts, strings.Join(sortedKeys(img.Config.ExposedPorts), ","))
+
+	for k, v := range img.Config.Labels {
+		m[k] = v
+	}
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// parseUser parses a numeric `uid` or `uid:gid` user specification, for use
+// when no root filesystem is available to resolve names.
+func parseUser(user string) (User, error) {
+	name, group, hasGroup := strings.Cut(user, ":")
+	uid, err := parseID(name)
+	if err != nil {
+		return User{}, fmt.Errorf("cannot resolve user %q: user names require a root filesystem", user)
+	}
+	u := User{UID: uid}
+	if hasGroup {
+		gid, err := parseID(group)
+		if err != nil {
+			return User{}, fmt.Errorf("cannot resolve user %q: group names require a root filesystem", user)
+		}
+		u.GID = gid
+	}
+	return u, nil
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
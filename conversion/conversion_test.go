@@ -0,0 +1,182 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conversion
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestVerbatimFields(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		config   v1.ImageConfig
+		opts     Options
+		expected Process
+	}{
+		{
+			name:     "empty",
+			expected: Process{Cwd: "/"},
+		},
+		{
+			name: "working directory and environment",
+			config: v1.ImageConfig{
+				WorkingDir: "/home/alice",
+				Env:        []string{"PATH=/usr/bin:/bin", "FOO=bar"},
+			},
+			expected: Process{Cwd: "/home/alice", Env: []string{"PATH=/usr/bin:/bin", "FOO=bar"}},
+		},
+		{
+			name:     "entrypoint only",
+			config:   v1.ImageConfig{Entrypoint: []string{"/bin/sh", "-c"}},
+			expected: Process{Cwd: "/", Args: []string{"/bin/sh", "-c"}},
+		},
+		{
+			name:     "cmd only",
+			config:   v1.ImageConfig{Cmd: []string{"echo", "hello"}},
+			expected: Process{Cwd: "/", Args: []string{"echo", "hello"}},
+		},
+		{
+			name:     "cmd is appended to entrypoint",
+			config:   v1.ImageConfig{Entrypoint: []string{"/bin/sh", "-c"}, Cmd: []string{"echo hello"}},
+			expected: Process{Cwd: "/", Args: []string{"/bin/sh", "-c", "echo hello"}},
+		},
+		{
+			name:     "args override",
+			config:   v1.ImageConfig{Entrypoint: []string{"/bin/sh", "-c"}, Cmd: []string{"echo hello"}},
+			opts:     Options{Args: []string{"/bin/true"}},
+			expected: Process{Cwd: "/", Args: []string{"/bin/true"}},
+		},
+		{
+			name:     "env override",
+			config:   v1.ImageConfig{Env: []string{"PATH=/usr/bin:/bin", "FOO=bar"}},
+			opts:     Options{Env: []string{"FOO=baz", "EXTRA=1"}},
+			expected: Process{Cwd: "/", Env: []string{"PATH=/usr/bin:/bin", "FOO=baz", "EXTRA=1"}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := Convert(v1.Image{Config: tt.config}, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(spec.Process, tt.expected) {
+				t.Errorf("unexpected process: %+v != %+v", spec.Process, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAnnotationFields(t *testing.T) {
+	created := time.Date(2015, 10, 31, 22, 22, 56, 15925234, time.UTC)
+	img := v1.Image{
+		Created: &created,
+		Author:  "Alyssa P. Hacker <alyspdev@example.com>",
+		Platform: v1.Platform{
+			Architecture: "amd64",
+			OS:           "windows",
+			OSVersion:    "10.0.14393.1066",
+			OSFeatures:   []string{"win32k"},
+			Variant:      "v1",
+		},
+		Config: v1.ImageConfig{
+			StopSignal:   "SIGKILL",
+			ExposedPorts: map[string]struct{}{"8080/tcp": {}, "53/udp": {}},
+			Labels: map[string]string{
+				"com.example.key":    "value",
+				AnnotationVariant:    "from-labels",
+				AnnotationStopSignal: "SIGTERM",
+			},
+		},
+	}
+	expected := map[string]string{
+		AnnotationOS:           "windows",
+		AnnotationArchitecture: "amd64",
+		AnnotationVariant:      "from-labels",
+		AnnotationOSVersion:    "10.0.14393.1066",
+		AnnotationOSFeatures:   "win32k",
+		AnnotationAuthor:       "Alyssa P. Hacker <alyspdev@example.com>",
+		v1.AnnotationCreated:   "2015-10-31T22:22:56.015925234Z",
+		AnnotationStopSignal:   "SIGTERM",
+		AnnotationExposedPorts: "53/udp,8080/tcp",
+		"com.example.key":      "value",
+	}
+
+	spec, err := Convert(img, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(spec.Annotations, expected) {
+		t.Errorf("unexpected annotations: %v", spec.Annotations)
+	}
+}
+
+func TestParsedFields(t *testing.T) {
+	for _, tt := range []struct {
+		user     string
+		opts     Options
+		expected User
+		fail     bool
+	}{
+		{user: "", expected: User{}},
+		{user: "1000", expected: User{UID: 1000}},
+		{user: "1000:1001", expected: User{UID: 1000, GID: 1001}},
+		{user: "1000", opts: Options{User: "2000:2001"}, expected: User{UID: 2000, GID: 2001}},
+		{user: "alice", fail: true},
+		{user: "1000:staff", fail: true},
+		{user: "-1", fail: true},
+	} {
+		t.Run(tt.user, func(t *testing.T) {
+			spec, err := Convert(v1.Image{Config: v1.ImageConfig{User: tt.user}}, tt.opts)
+			if tt.fail {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", spec.Process.User)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(spec.Process.User, tt.expected) {
+				t.Errorf("unexpected user: %+v != %+v", spec.Process.User, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOptionalFields(t *testing.T) {
+	img := v1.Image{Config: v1.ImageConfig{
+		Volumes: map[string]struct{}{"/var/log/my-app-logs": {}, "/var/job-result-data": {}},
+	}}
+
+	spec, err := Convert(img, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Mounts != nil {
+		t.Errorf("volumes should only be converted on request: %v", spec.Mounts)
+	}
+
+	spec, err = Convert(img, Options{Volumes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Mount{{Destination: "/var/job-result-data"}, {Destination: "/var/log/my-app-logs"}}
+	if !reflect.DeepEqual(spec.Mounts, expected) {
+		t.Errorf("unexpected mounts: %v", spec.Mounts)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conversion
+
+// Spec is the subset of the OCI runtime configuration (`config.json`) that is
+// generated from an image configuration. Field names and JSON encoding match
+// the corresponding types of github.com/opencontainers/runtime-spec/specs-go,
+// so a Spec can be marshalled into, or copied field by field onto, a full
+// runtime configuration.
+type Spec struct {
+	// Process configures the container process.
+	Process Process `json:"process"`
+
+	// Mounts configures additional mounts, generated from Config.Volumes.
+	Mounts []Mount `json:"mounts,omitempty"`
+
+	// Annotations contains arbitrary metadata for the container.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Process contains information to start a specific application inside the container.
+type Process struct {
+	// User specifies user information for the process.
+	User User `json:"user"`
+
+	// Args specifies the binary and arguments for the application to execute.
+	Args []string `json:"args,omitempty"`
+
+	// Env populates the process environment for the process.
+	Env []string `json:"env,omitempty"`
+
+	// Cwd is the current working directory for the process and must be
+	// relative to the container's root.
+	Cwd string `json:"cwd"`
+}
+
+// User specifies specific user (and group) information for the container process.
+type User struct {
+	// UID is the user id.
+	UID uint32 `json:"uid"`
+
+	// GID is the group id.
+	GID uint32 `json:"gid"`
+
+	// AdditionalGids are additional group ids set for the container's process.
+	AdditionalGids []uint32 `json:"additionalGids,omitempty"`
+}
+
+// Mount specifies a mount for a container.
+type Mount struct {
+	// Destination is the absolute path where the mount will be placed in the container.
+	Destination string `json:"destination"`
+
+	// Type specifies the mount kind.
+	Type string `json:"type,omitempty"`
+
+	// Source specifies the source path of the mount.
+	Source string `json:"source,omitempty"`
+
+	// Options are fstab style mount options.
+	Options []string `json:"options,omitempty"`
+}
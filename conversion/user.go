@@ -0,0 +1,251 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conversion
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	passwdFile = "etc/passwd"
+	groupFile  = "etc/group"
+)
+
+var (
+	// ErrUnknownUser is returned when a user name cannot be found in `/etc/passwd`.
+	ErrUnknownUser = errors.New("unknown user")
+
+	// ErrUnknownGroup is returned when a group name cannot be found in `/etc/group`.
+	ErrUnknownGroup = errors.New("unknown group")
+)
+
+// ResolveUser resolves a Config.User value (`user`, `uid`, `user:group`,
+// `uid:gid`, `uid:group` or `user:gid`) against the `/etc/passwd` and
+// `/etc/group` files of rootfs, following the "Config.User" section of
+// conversion.md:
+//
+//   - Numeric values are used verbatim, even if they have no entry.
+//   - A name that has no entry is an error wrapping ErrUnknownUser or
+//     ErrUnknownGroup.
+//   - If no group is given, the primary group of the user from
+//     `/etc/passwd` is used, or gid 0 for a numeric uid without an entry.
+//   - Supplementary groups from `/etc/group` are only applied when the user
+//     is given by name and no group is given.
+//
+// A missing `/etc/passwd` or `/etc/group` is treated as empty. As fs.FS
+// implementations such as os.DirFS follow symlinks out of their root, these
+// files and `/etc` are refused if they are symlinks, which would be resolved
+// against the host instead of rootfs.
+func ResolveUser(rootfs fs.FS, user string) (User, error) {
+	name, group, hasGroup := strings.Cut(user, ":")
+	if name == "" || hasGroup && group == "" {
+		return User{}, fmt.Errorf("invalid user %q", user)
+	}
+
+	var (
+		u        User
+		username string
+	)
+	if uid, err := parseID(name); err == nil {
+		u.UID = uid
+		entry, found, err := findPasswd(rootfs, func(e passwdEntry) bool { return e.uid == uid })
+		if err != nil {
+			return User{}, err
+		}
+		if found {
+			u.GID = entry.gid
+		}
+	} else {
+		entry, found, err := findPasswd(rootfs, func(e passwdEntry) bool { return e.name == name })
+		if err != nil {
+			return User{}, err
+		}
+		if !found {
+			return User{}, fmt.Errorf("cannot resolve user %q: %w: %s", user, ErrUnknownUser, name)
+		}
+		u.UID, u.GID, username = entry.uid, entry.gid, entry.name
+	}
+
+	if hasGroup {
+		gid, err := parseID(group)
+		if err != nil {
+			entry, found, err := findGroup(rootfs, func(e groupEntry) bool { return e.name == group })
+			if err != nil {
+				return User{}, err
+			}
+			if !found {
+				return User{}, fmt.Errorf("cannot resolve user %q: %w: %s", user, ErrUnknownGroup, group)
+			}
+			gid = entry.gid
+		}
+		u.GID = gid
+		return u, nil
+	}
+
+	if username != "" {
+		if err := eachGroup(rootfs, func(e groupEntry) {
+			if e.gid != u.GID && e.hasMember(username) {
+				u.AdditionalGids = append(u.AdditionalGids, e.gid)
+			}
+		}); err != nil {
+			return User{}, err
+		}
+	}
+	return u, nil
+}
+
+func parseID(s string) (uint32, error) {
+	id, err := strconv.ParseUint(s, 10, 32)
+	return uint32(id), err
+}
+
+type passwdEntry struct {
+	name     string
+	uid, gid uint32
+}
+
+type groupEntry struct {
+	name    string
+	gid     uint32
+	members []string
+}
+
+func (e groupEntry) hasMember(name string) bool {
+	for _, m := range e.members {
+		if m == name {
+			return true
+		}
+	}
+	return false
+}
+
+// findPasswd returns the first entry of `/etc/passwd` matching fn.
+func findPasswd(rootfs fs.FS, fn func(passwdEntry) bool) (passwdEntry, bool, error) {
+	var (
+		entry passwdEntry
+		found bool
+	)
+	err := eachLine(rootfs, passwdFile, func(fields []string) {
+		// name:password:UID:GID:GECOS:directory:shell
+		if found || len(fields) < 4 {
+			return
+		}
+		uid, err := parseID(fields[2])
+		if err != nil {
+			return
+		}
+		gid, err := parseID(fields[3])
+		if err != nil {
+			return
+		}
+		e := passwdEntry{name: fields[0], uid: uid, gid: gid}
+		if fn(e) {
+			entry, found = e, true
+		}
+	})
+	return entry, found, err
+}
+
+// findGroup returns the first entry of `/etc/group` matching fn.
+func findGroup(rootfs fs.FS, fn func(groupEntry) bool) (groupEntry, bool, error) {
+	var (
+		entry groupEntry
+		found bool
+	)
+	err := eachGroup(rootfs, func(e groupEntry) {
+		if !found && fn(e) {
+			entry, found = e, true
+		}
+	})
+	return entry, found, err
+}
+
+func eachGroup(rootfs fs.FS, fn func(groupEntry)) error {
+	return eachLine(rootfs, groupFile, func(fields []string) {
+		// name:password:GID:member,member
+		if len(fields) < 3 {
+			return
+		}
+		gid, err := parseID(fields[2])
+		if err != nil {
+			return
+		}
+		e := groupEntry{name: fields[0], gid: gid}
+		if len(fields) > 3 && fields[3] != "" {
+			e.members = strings.Split(fields[3], ",")
+		}
+		fn(e)
+	})
+}
+
+// eachLine calls fn with the colon separated fields of every non-empty,
+// non-comment line of the named file. A missing file has no lines.
+func eachLine(rootfs fs.FS, name string, fn func([]string)) error {
+	if err := checkNoSymlink(rootfs, name); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	f, err := rootfs.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open /%s: %w", name, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fn(strings.Split(line, ":"))
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read /%s: %w", name, err)
+	}
+	return nil
+}
+
+// checkNoSymlink returns an error if the named file or one of its parent
+// directories is a symlink. fs.FS has no Lstat, so the type of each element
+// is read from its parent directory, starting from the root.
+func checkNoSymlink(rootfs fs.FS, name string) error {
+	dir := "."
+	for _, elem := range strings.Split(name, "/") {
+		entries, err := fs.ReadDir(rootfs, dir)
+		if err != nil {
+			return fmt.Errorf("failed to read /%s: %w", name, err)
+		}
+		i := sort.Search(len(entries), func(i int) bool { return entries[i].Name() >= elem })
+		if i == len(entries) || entries[i].Name() != elem {
+			return fmt.Errorf("failed to read /%s: %w", name, fs.ErrNotExist)
+		}
+		dir = path.Join(dir, elem)
+		if entries[i].Type()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("/%s is a symlink, which may point out of the root filesystem", dir)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conversion
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var rootfs = fstest.MapFS{
+	"etc/passwd": &fstest.MapFile{Data: []byte(`# comment
+root:x:0:0:root:/root:/bin/sh
+alice:x:1000:1000:Alyssa P. Hacker:/home/alice:/bin/sh
+bob:x:1001:100::/home/bob:/bin/sh
+broken:x:notanumber:0::/:/bin/sh
+`)},
+	"etc/group": &fstest.MapFile{Data: []byte(`root:x:0:
+users:x:100:alice
+alice:x:1000:
+wheel:x:10:alice,bob
+docker:x:999:bob
+`)},
+}
+
+func TestResolveUser(t *testing.T) {
+	for _, tt := range []struct {
+		user     string
+		expected User
+		err      error
+	}{
+		{user: "root", expected: User{UID: 0, GID: 0}},
+		{user: "alice", expected: User{UID: 1000, GID: 1000, AdditionalGids: []uint32{100, 10}}},
+		{user: "bob", expected: User{UID: 1001, GID: 100, AdditionalGids: []uint32{10, 999}}},
+		{user: "alice:wheel", expected: User{UID: 1000, GID: 10}},
+		{user: "alice:4242", expected: User{UID: 1000, GID: 4242}},
+		{user: "1001", expected: User{UID: 1001, GID: 100}},
+		{user: "1001:docker", expected: User{UID: 1001, GID: 999}},
+		{user: "4242", expected: User{UID: 4242, GID: 0}},
+		{user: "4242:4343", expected: User{UID: 4242, GID: 4343}},
+		{user: "mallory", err: ErrUnknownUser},
+		{user: "broken", err: ErrUnknownUser},
+		{user: "alice:nogroup", err: ErrUnknownGroup},
+		{user: "4242:nogroup", err: ErrUnknownGroup},
+	} {
+		t.Run(tt.user, func(t *testing.T) {
+			u, err := ResolveUser(rootfs, tt.user)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected %v, got %v (%+v)", tt.err, err, u)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(u, tt.expected) {
+				t.Errorf("unexpected user: %+v != %+v", u, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveUserInvalid(t *testing.T) {
+	for _, user := range []string{":", "alice:", ":1000"} {
+		if _, err := ResolveUser(rootfs, user); err == nil {
+			t.Errorf("expected %q to be rejected", user)
+		}
+	}
+}
+
+func TestResolveUserEmptyRootFS(t *testing.T) {
+	u, err := ResolveUser(fstest.MapFS{}, "1000:1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(u, User{UID: 1000, GID: 1000}) {
+		t.Errorf("unexpected user: %+v", u)
+	}
+	if _, err := ResolveUser(fstest.MapFS{}, "alice"); !errors.Is(err, ErrUnknownUser) {
+		t.Errorf("expected ErrUnknownUser, got %v", err)
+	}
+}
+
+func TestResolveUserSymlink(t *testing.T) {
+	host := t.TempDir()
+	if err := os.WriteFile(filepath.Join(host, "passwd"), []byte("alice:x:1000:1000::/home/alice:/bin/sh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name   string
+		link   string
+		target string
+		user   string
+	}{
+		{name: "passwd", link: "etc/passwd", target: filepath.Join(host, "passwd"), user: "alice"},
+		{name: "group", link: "etc/group", target: filepath.Join(host, "passwd"), user: "1000:alice"},
+		{name: "etc", link: "etc", target: host, user: "alice"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, tt.link)), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Symlink(tt.target, filepath.Join(dir, tt.link)); err != nil {
+				t.Skip(err)
+			}
+			if u, err := ResolveUser(os.DirFS(dir), tt.user); err == nil || errors.Is(err, ErrUnknownUser) || errors.Is(err, ErrUnknownGroup) {
+				t.Errorf("expected the symlink to be refused, got %+v, %v", u, err)
+			}
+		})
+	}
+
+	// fstest.MapFS reports symlinks without following them
+	fsys := fstest.MapFS{"etc/passwd": &fstest.MapFile{Data: []byte("/etc/passwd"), Mode: fs.ModeSymlink}}
+	if _, err := ResolveUser(fsys, "1000"); err == nil {
+		t.Error("expected the symlink to be refused")
+	}
+}
+
+func TestConvertWithRootFS(t *testing.T) {
+	spec, err := Convert(v1.Image{Config: v1.ImageConfig{User: "bob"}}, Options{RootFS: rootfs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := User{UID: 1001, GID: 100, AdditionalGids: []uint32{10, 999}}
+	if !reflect.DeepEqual(spec.Process.User, expected) {
+		t.Errorf("unexpected user: %+v != %+v", spec.Process.User, expected)
+	}
+}
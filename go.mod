@@ -5,4 +5,14 @@ module github.com/opencontainers/image-spec
 // For example, updating this version to 1.19 first requires Go 1.21 to be released.
 go 1.18
 
-require github.com/opencontainers/go-digest v1.0.0
+require (
+	github.com/klauspost/compress v1.16.7
+	github.com/opencontainers/go-digest v1.0.1-0.20231025023718-d50d2fec9c98
+	github.com/opencontainers/go-digest/blake3 v0.0.0-20231025023718-d50d2fec9c98
+)
+
+require (
+	github.com/klauspost/cpuid/v2 v2.2.5 // indirect
+	github.com/zeebo/blake3 v0.2.3 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+)
@@ -0,0 +1,417 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package graph walks the graphs of content formed by descriptors, such as
+// an index referencing manifests referencing their config and layers.
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/content"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// SkipChildren is returned by a pre-order Handler to skip the children of a
+// node. It is not returned by Walk.
+var SkipChildren = errors.New("skip children") //nolint:revive // named like fs.SkipDir
+
+// ErrCycle is returned by Walk when a node is its own ancestor.
+var ErrCycle = errors.New("cycle in content graph")
+
+// MaxBlobSize is the maximum size of the blobs fetched to find their
+// children.
+const MaxBlobSize = 4 << 20
+
+// Node is a node visited by Walk.
+type Node struct {
+	// Descriptor is the descriptor of the node.
+	Descriptor v1.Descriptor
+
+	// Parents are the descriptors of the ancestors of the node, from the
+	// root to its parent. It is empty for the root.
+	Parents []v1.Descriptor
+}
+
+// Handler is called for the nodes visited by Walk.
+type Handler func(ctx context.Context, node Node) error
+
+// ChildrenFunc returns the descriptors referenced by blob, the content of a
+// node of a given media type.
+type ChildrenFunc func(blob []byte) ([]v1.Descriptor, error)
+
+// Options are the options of Walk.
+type Options struct {
+	// PreOrder is called for each node before its children are walked.
+	// Returning SkipChildren skips them.
+	PreOrder Handler
+
+	// PostOrder is called for each node after its children are walked,
+	// including when they are skipped.
+	PostOrder Handler
+
+	// Concurrency is the maximum number of nodes fetched or handled
+	// concurrently. If it is 0 or 1, the graph is walked sequentially, in
+	// the order of the children of each node.
+	Concurrency int
+
+	// Visited, if not nil, is the set of digests of the nodes whose children
+	// were walked, which are not visited again. It can be shared across
+	// walks. Nodes without children, as their media type has no ChildrenFunc
+	// or a pre-order handler skipped them, are visited for each reference,
+	// such that a digest reached first as a layer, or through an invalid
+	// descriptor, is still walked as a manifest. If nil, content referenced
+	// several times is visited for each reference.
+	Visited *Set
+
+	// Subject also walks the subjects of manifests and indexes, as children.
+	Subject bool
+
+	// MediaTypes maps media types to the functions returning the children
+	// of their nodes, in addition to or replacing ChildrenFuncs.
+	MediaTypes map[string]ChildrenFunc
+}
+
+// ChildrenFuncs are the functions returning the children of the media types
+// of manifests and indexes: the config and layers of manifests, and the
+// manifests of indexes. Subjects are not included.
+var ChildrenFuncs = map[string]ChildrenFunc{
+	v1.MediaTypeImageManifest:                                   manifestChildren,
+	v1.MediaTypeImageIndex:                                      indexChildren,
+	"application/vnd.docker.distribution.manifest.v2+json":      manifestChildren,
+	"application/vnd.docker.distribution.manifest.list.v2+json": indexChildren,
+}
+
+func manifestChildren(blob []byte) ([]v1.Descriptor, error) {
+	var manifest v1.Manifest
+	if err := json.Unmarshal(blob, &manifest); err != nil {
+		return nil, err
+	}
+	return append([]v1.Descriptor{manifest.Config}, manifest.Layers...), nil
+}
+
+func indexChildren(blob []byte) ([]v1.Descriptor, error) {
+	var index v1.Index
+	if err := json.Unmarshal(blob, &index); err != nil {
+		return nil, err
+	}
+	return index.Manifests, nil
+}
+
+// Set is a set of digests, safe for concurrent use.
+type Set struct {
+	mu      sync.Mutex
+	digests map[digest.Digest]struct{}
+}
+
+// NewSet returns an empty Set.
+func NewSet() *Set {
+	return &Set{digests: map[digest.Digest]struct{}{}}
+}
+
+// Add adds d to the set, and returns whether it was not in it.
+func (s *Set) Add(d digest.Digest) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.digests[d]; ok {
+		return false
+	}
+	s.digests[d] = struct{}{}
+	return true
+}
+
+// Contains returns whether d is in the set.
+func (s *Set) Contains(d digest.Digest) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.digests[d]
+	return ok
+}
+
+// Len returns the number of digests in the set.
+func (s *Set) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.digests)
+}
+
+// Walk walks the graph rooted at root, fetching from f, and verifying, the
+// nodes whose media type has a ChildrenFunc to find their children. The
+// first error returned by f or a handler stops the walk and is returned.
+//
+// With Visited, a node reached again while another branch of a concurrent
+// walk is walking it waits for that walk, such that the post-order handler
+// of a node is always called after those of its children. A cycle across
+// branches fails with ErrCycle instead.
+func Walk(ctx context.Context, f content.Fetcher, root v1.Descriptor, opts Options) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := &walker{f: f, opts: opts, cancel: cancel, nodes: map[digest.Digest]*visit{}}
+	if opts.Concurrency > 1 {
+		w.sem = make(chan struct{}, opts.Concurrency)
+	}
+	err := w.walk(ctx, Node{Descriptor: root})
+	if w.err != nil {
+		// the error that canceled the other branches of the walk
+		return w.err
+	}
+	return err
+}
+
+type walker struct {
+	f      content.Fetcher
+	opts   Options
+	sem    chan struct{} // nil when sequential
+	cancel context.CancelFunc
+
+	once sync.Once
+	err  error // first error of a concurrent walk
+
+	mu    sync.Mutex
+	nodes map[digest.Digest]*visit // nodes being walked, with Visited
+}
+
+// visit is the walk of a node with children, which other branches reaching
+// the node wait for.
+type visit struct {
+	done     chan struct{}
+	expanded bool            // set before done is closed
+	children []digest.Digest // set once the children are known
+}
+
+// fail stops a concurrent walk because of err.
+func (w *walker) fail(err error) {
+	w.once.Do(func() {
+		w.err = err
+		w.cancel()
+	})
+}
+
+func (w *walker) walk(ctx context.Context, node Node) error {
+	desc := node.Descriptor
+	for _, parent := range node.Parents {
+		if parent.Digest == desc.Digest {
+			return fmt.Errorf("%w: %s", ErrCycle, desc.Digest)
+		}
+	}
+	fn := w.childrenFunc(desc.MediaType)
+	var v *visit
+	if fn != nil && w.opts.Visited != nil {
+		var err error
+		if v, err = w.claim(ctx, node); v == nil || err != nil {
+			return err
+		}
+		defer w.release(desc.Digest, v)
+	}
+
+	var children []v1.Descriptor
+	err := w.do(ctx, func() error {
+		if w.opts.PreOrder != nil {
+			err := w.opts.PreOrder(ctx, node)
+			if errors.Is(err, SkipChildren) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if fn == nil {
+			return nil
+		}
+		var err error
+		children, err = w.children(ctx, desc, fn)
+		if err == nil && v != nil {
+			w.expand(v, children)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(children) > 0 {
+		parents := make([]v1.Descriptor, len(node.Parents), len(node.Parents)+1)
+		copy(parents, node.Parents)
+		parents = append(parents, desc)
+		if err := w.walkAll(ctx, children, parents); err != nil {
+			return err
+		}
+	}
+
+	if w.opts.PostOrder == nil {
+		return nil
+	}
+	return w.do(ctx, func() error {
+		if err := w.opts.PostOrder(ctx, node); err != nil && !errors.Is(err, SkipChildren) {
+			return err
+		}
+		return nil
+	})
+}
+
+// claim returns the visit of node, to be released once it is walked, or nil
+// if the node was expanded by another branch or walk. It waits for the
+// branch walking the node, if any.
+func (w *walker) claim(ctx context.Context, node Node) (*visit, error) {
+	d := node.Descriptor.Digest
+	for {
+		w.mu.Lock()
+		v, ok := w.nodes[d]
+		switch {
+		case !ok && w.opts.Visited.Contains(d):
+			w.mu.Unlock()
+			return nil, nil
+		case !ok:
+			v = &visit{done: make(chan struct{})}
+			w.nodes[d] = v
+			w.mu.Unlock()
+			return v, nil
+		case len(node.Parents) > 0 && w.reaches(d, node.Parents[len(node.Parents)-1].Digest):
+			// the other branch waits for the parent of node
+			w.mu.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrCycle, d)
+		}
+		w.mu.Unlock()
+
+		select {
+		case <-v.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if v.expanded {
+			return nil, nil
+		}
+		// the children of the node were skipped by the other branch, so
+		// they are walked from this one
+	}
+}
+
+// expand records the children of the node of v, once fetched.
+func (w *walker) expand(v *visit, children []v1.Descriptor) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	v.expanded = true
+	for _, child := range children {
+		v.children = append(v.children, child.Digest)
+	}
+}
+
+// reaches returns whether the node from, being walked, reaches the node to
+// through the known children of the nodes being walked. w.mu must be held.
+func (w *walker) reaches(from, to digest.Digest) bool {
+	seen := map[digest.Digest]bool{}
+	stack := []digest.Digest{from}
+	for len(stack) > 0 {
+		d := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if d == to {
+			return true
+		}
+		if v, ok := w.nodes[d]; ok && !seen[d] {
+			seen[d] = true
+			stack = append(stack, v.children...)
+		}
+	}
+	return false
+}
+
+// release ends the visit v of d, adding d to Visited if its children were
+// walked.
+func (w *walker) release(d digest.Digest, v *visit) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if v.expanded {
+		w.opts.Visited.Add(d)
+	}
+	delete(w.nodes, d)
+	close(v.done)
+}
+
+// walkAll walks the children of a node, concurrently unless the walk is
+// sequential.
+func (w *walker) walkAll(ctx context.Context, children []v1.Descriptor, parents []v1.Descriptor) error {
+	if w.sem == nil {
+		for _, child := range children {
+			if err := w.walk(ctx, Node{Descriptor: child, Parents: parents}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	errs := make(chan error, len(children))
+	for _, child := range children {
+		go func(child v1.Descriptor) {
+			err := w.walk(ctx, Node{Descriptor: child, Parents: parents})
+			if err != nil {
+				w.fail(err)
+			}
+			errs <- err
+		}(child)
+	}
+	var first error
+	for range children {
+		if err := <-errs; err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// childrenFunc returns the ChildrenFunc of mediaType, or nil if it has none.
+func (w *walker) childrenFunc(mediaType string) ChildrenFunc {
+	if fn, ok := w.opts.MediaTypes[mediaType]; ok {
+		return fn
+	}
+	return ChildrenFuncs[mediaType]
+}
+
+// children fetches the blob of desc and returns its children, found by fn.
+func (w *walker) children(ctx context.Context, desc v1.Descriptor, fn ChildrenFunc) ([]v1.Descriptor, error) {
+	blob, err := content.ReadAll(ctx, w.f, desc, MaxBlobSize)
+	if err != nil {
+		return nil, err
+	}
+	children, err := fn(blob)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", desc.Digest, err)
+	}
+	if w.opts.Subject {
+		var header struct {
+			Subject *v1.Descriptor `json:"subject"`
+		}
+		if json.Unmarshal(blob, &header) == nil && header.Subject != nil {
+			children = append(children, *header.Subject)
+		}
+	}
+	return children, nil
+}
+
+// do calls fn, holding a slot of the concurrency limit.
+func (w *walker) do(ctx context.Context, fn func() error) error {
+	if w.sem != nil {
+		select {
+		case w.sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		defer func() { <-w.sem }()
+	}
+	return fn()
+}
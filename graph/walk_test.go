@@ -0,0 +1,448 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/builder"
+	"github.com/opencontainers/image-spec/content"
+	"github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// testGraph is an index of two images sharing a layer, and a referrer of the
+// first image, in a memory store. Nodes are named after their content.
+type testGraph struct {
+	store    *content.Memory
+	index    v1.Descriptor
+	referrer v1.Descriptor
+	names    map[digest.Digest]string
+}
+
+func (g *testGraph) push(t *testing.T, name, mediaType string, blob []byte) v1.Descriptor {
+	t.Helper()
+	desc := v1.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(blob), Size: int64(len(blob))}
+	if err := g.store.Push(context.Background(), desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal(err)
+	}
+	g.names[desc.Digest] = name
+	return desc
+}
+
+// buildable is a builder.Manifest or builder.Index.
+type buildable interface {
+	Build() (builder.Result, error)
+}
+
+func (g *testGraph) pushResult(t *testing.T, name string, m buildable) v1.Descriptor {
+	t.Helper()
+	res, err := m.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g.push(t, name, res.Descriptor.MediaType, res.Bytes)
+}
+
+func newTestGraph(t *testing.T) *testGraph {
+	t.Helper()
+	g := &testGraph{store: content.NewMemory(), names: map[digest.Digest]string{}}
+	shared := g.push(t, "shared", v1.MediaTypeImageLayer, []byte("shared"))
+	var manifests []v1.Descriptor
+	for _, name := range []string{"a", "b"} {
+		config := g.push(t, name+".config", v1.MediaTypeImageConfig, []byte(`{"name":"`+name+`"}`))
+		layer := g.push(t, name+".layer", v1.MediaTypeImageLayer, []byte(name))
+		manifests = append(manifests, g.pushResult(t, name, builder.NewManifest().Validate(builder.NoValidation).ConfigDescriptor(config).LayerDescriptor(shared).LayerDescriptor(layer)))
+	}
+	// an index built by hand, as the builder sorts its manifests
+	index, err := json.Marshal(v1.Index{Versioned: specs.Versioned{SchemaVersion: 2}, MediaType: v1.MediaTypeImageIndex, Manifests: manifests})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.index = g.push(t, "index", v1.MediaTypeImageIndex, index)
+	g.push(t, "empty", v1.MediaTypeEmptyJSON, v1.DescriptorEmptyJSON.Data)
+	g.referrer = g.pushResult(t, "referrer", builder.NewManifest().Validate(builder.NoValidation).ArtifactType("application/example").Subject(manifests[0]))
+	return g
+}
+
+// recorder records the nodes visited.
+type recorder struct {
+	g      *testGraph
+	mu     sync.Mutex
+	visits []string
+}
+
+func (r *recorder) handler(prefix string) Handler {
+	return func(_ context.Context, node Node) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.visits = append(r.visits, prefix+r.g.names[node.Descriptor.Digest])
+		return nil
+	}
+}
+
+func (r *recorder) String() string {
+	return strings.Join(r.visits, " ")
+}
+
+func TestWalkOrder(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGraph(t)
+
+	for _, tt := range []struct {
+		name     string
+		root     v1.Descriptor
+		opts     func(r *recorder) Options
+		expected string
+	}{
+		{
+			name:     "pre-order",
+			root:     g.index,
+			opts:     func(r *recorder) Options { return Options{PreOrder: r.handler("")} },
+			expected: "index a a.config shared a.layer b b.config shared b.layer",
+		},
+		{
+			name:     "post-order",
+			root:     g.index,
+			opts:     func(r *recorder) Options { return Options{PostOrder: r.handler("")} },
+			expected: "a.config shared a.layer a b.config shared b.layer b index",
+		},
+		{
+			name: "both",
+			root: g.index,
+			opts: func(r *recorder) Options {
+				return Options{PreOrder: r.handler("+"), PostOrder: r.handler("-"), Visited: NewSet()}
+			},
+			expected: "+index +a +a.config -a.config +shared -shared +a.layer -a.layer -a +b +b.config -b.config +shared -shared +b.layer -b.layer -b -index",
+		},
+		{
+			name: "skip",
+			root: g.index,
+			opts: func(r *recorder) Options {
+				return Options{
+					PreOrder: func(ctx context.Context, node Node) error {
+						r.handler("")(ctx, node)
+						if g.names[node.Descriptor.Digest] == "a" {
+							return SkipChildren
+						}
+						return nil
+					},
+				}
+			},
+			expected: "index a b b.config shared b.layer",
+		},
+		{
+			name:     "subject",
+			root:     g.referrer,
+			opts:     func(r *recorder) Options { return Options{PreOrder: r.handler(""), Subject: true} },
+			expected: "referrer empty a a.config shared a.layer",
+		},
+		{
+			name:     "without subject",
+			root:     g.referrer,
+			opts:     func(r *recorder) Options { return Options{PreOrder: r.handler("")} },
+			expected: "referrer empty",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{g: g}
+			if err := Walk(ctx, g.store, tt.root, tt.opts(r)); err != nil {
+				t.Fatal(err)
+			}
+			if r.String() != tt.expected {
+				t.Errorf("unexpected walk:\n%s\nexpected:\n%s", r, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWalkParents(t *testing.T) {
+	g := newTestGraph(t)
+	var paths []string
+	err := Walk(context.Background(), g.store, g.index, Options{
+		PreOrder: func(_ context.Context, node Node) error {
+			if g.names[node.Descriptor.Digest] == "b.layer" {
+				for _, parent := range node.Parents {
+					paths = append(paths, g.names[parent.Digest])
+				}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(paths) != "[index b]" {
+		t.Errorf("unexpected parents: %v", paths)
+	}
+}
+
+func TestWalkConcurrency(t *testing.T) {
+	g := newTestGraph(t)
+	for _, concurrency := range []int{2, 4} {
+		var (
+			mu             sync.Mutex
+			active, maxAct int
+			visited        = NewSet()
+		)
+		handler := func(context.Context, Node) error {
+			mu.Lock()
+			active++
+			if active > maxAct {
+				maxAct = active
+			}
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			active--
+			mu.Unlock()
+			return nil
+		}
+		err := Walk(context.Background(), g.store, g.index, Options{
+			PreOrder:    handler,
+			PostOrder:   handler,
+			Concurrency: concurrency,
+			Visited:     visited,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if maxAct > concurrency {
+			t.Errorf("%d handlers called concurrently, expected at most %d", maxAct, concurrency)
+		}
+		// only the nodes with children
+		if visited.Len() != 3 {
+			t.Errorf("unexpected number of nodes visited: %d", visited.Len())
+		}
+	}
+}
+
+func TestWalkCustomMediaType(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGraph(t)
+	const listType = "application/example.list+json"
+	list := func(descs ...v1.Descriptor) []byte {
+		raw, err := json.Marshal(descs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+	parse := func(blob []byte) ([]v1.Descriptor, error) {
+		var descs []v1.Descriptor
+		err := json.Unmarshal(blob, &descs)
+		return descs, err
+	}
+	root := g.push(t, "list", listType, list(g.index, g.referrer))
+
+	r := &recorder{g: g}
+	opts := Options{PreOrder: r.handler(""), MediaTypes: map[string]ChildrenFunc{listType: parse}, Visited: NewSet()}
+	if err := Walk(ctx, g.store, root, opts); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "list index a a.config shared a.layer b b.config shared b.layer referrer empty"; r.String() != expected {
+		t.Errorf("unexpected walk:\n%s\nexpected:\n%s", r, expected)
+	}
+
+	// a custom media type whose node references itself
+	cyclic := g.push(t, "cyclic", listType+"+cyclic", []byte("cyclic"))
+	opts.MediaTypes[cyclic.MediaType] = func([]byte) ([]v1.Descriptor, error) { return []v1.Descriptor{cyclic}, nil }
+	if err := Walk(ctx, g.store, cyclic, Options{MediaTypes: opts.MediaTypes}); !errors.Is(err, ErrCycle) {
+		t.Errorf("unexpected error for a cycle: %v", err)
+	}
+}
+
+func TestWalkVisited(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGraph(t)
+	manifests := map[string]v1.Descriptor{}
+	err := Walk(ctx, g.store, g.index, Options{PreOrder: func(_ context.Context, node Node) error {
+		manifests[g.names[node.Descriptor.Digest]] = node.Descriptor
+		return nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a is reached first as a layer, then as a manifest
+	asLayer := manifests["a"]
+	asLayer.MediaType = v1.MediaTypeImageLayer
+	// b is reached first with an invalid size, whose children are skipped
+	invalid := manifests["b"]
+	invalid.Size++
+	list, err := json.Marshal(v1.Index{Versioned: specs.Versioned{SchemaVersion: 2}, MediaType: v1.MediaTypeImageIndex,
+		Manifests: []v1.Descriptor{asLayer, invalid, manifests["a"], manifests["b"]}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := g.push(t, "root", v1.MediaTypeImageIndex, list)
+
+	for _, concurrency := range []int{1, 4} {
+		r := &recorder{g: g}
+		err := Walk(ctx, g.store, root, Options{
+			PreOrder: func(_ context.Context, node Node) error {
+				if node.Descriptor.Digest == invalid.Digest && node.Descriptor.Size == invalid.Size {
+					return SkipChildren
+				}
+				return nil
+			},
+			PostOrder:   r.handler(""),
+			Concurrency: concurrency,
+			Visited:     NewSet(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"a.config", "a.layer", "b.config", "b.layer"} {
+			if !strings.Contains(" "+r.String()+" ", " "+name+" ") {
+				t.Errorf("concurrency %d: %s not visited: %s", concurrency, name, r)
+			}
+		}
+	}
+}
+
+func TestWalkPostOrderConcurrent(t *testing.T) {
+	// a shared manifest, reached concurrently by two branches, is handled
+	// after its children by both
+	ctx := context.Background()
+	g := newTestGraph(t)
+	var indexes []v1.Descriptor
+	for _, name := range []string{"x", "y"} {
+		var index v1.Index
+		rc, err := g.store.Fetch(ctx, g.index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = json.NewDecoder(rc).Decode(&index)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		index.Annotations = map[string]string{"name": name}
+		raw, err := json.Marshal(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		indexes = append(indexes, g.push(t, name, v1.MediaTypeImageIndex, raw))
+	}
+	raw, err := json.Marshal(v1.Index{Versioned: specs.Versioned{SchemaVersion: 2}, MediaType: v1.MediaTypeImageIndex, Manifests: indexes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := g.push(t, "root", v1.MediaTypeImageIndex, raw)
+
+	for i := 0; i < 20; i++ {
+		var (
+			mu   sync.Mutex
+			done = map[digest.Digest]bool{}
+		)
+		err := Walk(ctx, g.store, root, Options{
+			PostOrder: func(_ context.Context, node Node) error {
+				children, err := childrenOf(ctx, g.store, node.Descriptor)
+				if err != nil {
+					return err
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				for _, child := range children {
+					if !done[child.Digest] {
+						return fmt.Errorf("%s handled before its child %s", g.names[node.Descriptor.Digest], g.names[child.Digest])
+					}
+				}
+				done[node.Descriptor.Digest] = true
+				return nil
+			},
+			Concurrency: 4,
+			Visited:     NewSet(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func childrenOf(ctx context.Context, f content.Fetcher, desc v1.Descriptor) ([]v1.Descriptor, error) {
+	fn, ok := ChildrenFuncs[desc.MediaType]
+	if !ok {
+		return nil, nil
+	}
+	blob, err := content.ReadAll(ctx, f, desc, MaxBlobSize)
+	if err != nil {
+		return nil, err
+	}
+	return fn(blob)
+}
+
+func TestWalkCycleAcrossBranches(t *testing.T) {
+	// x and y reference each other, and are both children of the root
+	g := &testGraph{store: content.NewMemory(), names: map[digest.Digest]string{}}
+	const nodeType = "application/example.node"
+	x := g.push(t, "x", nodeType, []byte("x"))
+	y := g.push(t, "y", nodeType, []byte("y"))
+	root := g.push(t, "root", nodeType, []byte("root"))
+	children := map[string][]v1.Descriptor{"x": {y}, "y": {x}, "root": {x, y}}
+	parse := func(blob []byte) ([]v1.Descriptor, error) {
+		time.Sleep(time.Millisecond)
+		return children[string(blob)], nil
+	}
+	for _, concurrency := range []int{1, 4} {
+		err := Walk(context.Background(), g.store, root, Options{
+			MediaTypes:  map[string]ChildrenFunc{nodeType: parse},
+			Concurrency: concurrency,
+			Visited:     NewSet(),
+		})
+		if !errors.Is(err, ErrCycle) {
+			t.Errorf("concurrency %d: unexpected error for a cycle: %v", concurrency, err)
+		}
+	}
+}
+
+func TestWalkErrors(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGraph(t)
+	errStop := errors.New("stop")
+	err := Walk(ctx, g.store, g.index, Options{
+		PreOrder: func(_ context.Context, node Node) error {
+			if g.names[node.Descriptor.Digest] == "shared" {
+				return errStop
+			}
+			return nil
+		},
+		Concurrency: 2,
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	missing := v1.Descriptor{MediaType: v1.MediaTypeImageManifest, Digest: digest.FromString("missing"), Size: 7}
+	if err := Walk(ctx, g.store, missing, Options{}); !errors.Is(err, content.ErrNotFound) {
+		t.Errorf("unexpected error for a missing manifest: %v", err)
+	}
+
+	negative := g.index
+	negative.Size = -1
+	if err := Walk(ctx, g.store, negative, Options{Concurrency: 2}); err == nil {
+		t.Error("expected error for a negative size")
+	}
+}
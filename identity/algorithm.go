@@ -0,0 +1,99 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// AlgorithmPolicy decides whether a well-formed digest, whose algorithm is
+// not available, is accepted. It is called with the digest and the
+// digest.ErrDigestUnsupported error, and returns nil to accept the digest or
+// an error to reject it.
+//
+// Digests with an unknown algorithm cannot be verified: only their syntax
+// and the size of the content they describe are checked.
+type AlgorithmPolicy func(d digest.Digest, err error) error
+
+// AcceptUnknownAlgorithms is an AlgorithmPolicy accepting every digest.
+func AcceptUnknownAlgorithms(digest.Digest, error) error {
+	return nil
+}
+
+// RejectUnknownAlgorithms is an AlgorithmPolicy rejecting digests with an
+// unknown algorithm.
+func RejectUnknownAlgorithms(_ digest.Digest, err error) error {
+	return err
+}
+
+// WarnUnknownAlgorithms returns an AlgorithmPolicy accepting every digest,
+// calling warn for those with an unknown algorithm.
+func WarnUnknownAlgorithms(warn func(error)) AlgorithmPolicy {
+	return func(d digest.Digest, err error) error {
+		warn(fmt.Errorf("%s: %w", d, err))
+		return nil
+	}
+}
+
+// CheckAlgorithm validates d, and applies policy if its algorithm is not
+// available. A nil policy rejects unknown algorithms.
+//
+// It returns whether the digest can be verified, which is the case if its
+// algorithm is available.
+func CheckAlgorithm(d digest.Digest, policy AlgorithmPolicy) (bool, error) {
+	err := d.Validate()
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, digest.ErrDigestUnsupported) {
+		return false, err
+	}
+	if policy == nil {
+		policy = RejectUnknownAlgorithms
+	}
+	return false, policy(d, err)
+}
+
+// VerifyDescriptor checks that r, read until io.EOF, is the content described
+// by desc. Digests with an unknown algorithm are handled according to policy
+// and, when accepted, only the size of the content is verified.
+func VerifyDescriptor(desc v1.Descriptor, r io.Reader, policy AlgorithmPolicy) error {
+	verifiable, err := CheckAlgorithm(desc.Digest, policy)
+	if err != nil {
+		return err
+	}
+	w := io.Discard
+	var verifier digest.Verifier
+	if verifiable {
+		verifier = desc.Digest.Verifier()
+		w = verifier
+	}
+	n, err := io.Copy(w, io.LimitReader(r, desc.Size+1))
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", desc.Digest, err)
+	}
+	if n != desc.Size {
+		return fmt.Errorf("content %s has unexpected size", desc.Digest)
+	}
+	if verifier != nil && !verifier.Verified() {
+		return fmt.Errorf("content %s does not match its digest", desc.Digest)
+	}
+	return nil
+}
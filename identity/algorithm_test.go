@@ -0,0 +1,96 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestFromWith(t *testing.T) {
+	for _, tt := range []struct {
+		alg      digest.Algorithm
+		expected digest.Digest
+	}{
+		{alg: digest.SHA256, expected: "sha256:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{alg: digest.BLAKE3, expected: "blake3:6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85"},
+	} {
+		d, err := FromBytesWith(tt.alg, []byte("abc"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d != tt.expected {
+			t.Errorf("unexpected %s digest: %s", tt.alg, d)
+		}
+		d, err = FromReaderWith(tt.alg, strings.NewReader("abc"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d != tt.expected {
+			t.Errorf("unexpected %s digest from reader: %s", tt.alg, d)
+		}
+	}
+
+	if _, err := FromBytesWith("unknown", nil); !errors.Is(err, digest.ErrDigestUnsupported) {
+		t.Errorf("unexpected error for unknown algorithm: %v", err)
+	}
+	if _, err := FromReaderWith("unknown", strings.NewReader("")); !errors.Is(err, digest.ErrDigestUnsupported) {
+		t.Errorf("unexpected error for unknown algorithm: %v", err)
+	}
+}
+
+func TestVerifyDescriptor(t *testing.T) {
+	blob := []byte("content")
+	unknown := v1.Descriptor{Digest: "unknown:0123456789abcdef", Size: int64(len(blob))}
+	var warnings []error
+	warn := WarnUnknownAlgorithms(func(err error) { warnings = append(warnings, err) })
+
+	for _, tt := range []struct {
+		name   string
+		desc   v1.Descriptor
+		blob   []byte
+		policy AlgorithmPolicy
+		valid  bool
+	}{
+		{name: "sha256", desc: v1.Descriptor{Digest: digest.FromBytes(blob), Size: 7}, blob: blob, valid: true},
+		{name: "blake3", desc: v1.Descriptor{Digest: digest.BLAKE3.FromBytes(blob), Size: 7}, blob: blob, valid: true},
+		{name: "blake3 mismatch", desc: v1.Descriptor{Digest: digest.BLAKE3.FromString("other"), Size: 7}, blob: blob},
+		{name: "size mismatch", desc: v1.Descriptor{Digest: digest.BLAKE3.FromBytes(blob), Size: 6}, blob: blob},
+		{name: "malformed", desc: v1.Descriptor{Digest: "sha256:0123", Size: 7}, blob: blob, policy: AcceptUnknownAlgorithms},
+		{name: "unknown rejected by default", desc: unknown, blob: blob},
+		{name: "unknown rejected", desc: unknown, blob: blob, policy: RejectUnknownAlgorithms},
+		{name: "unknown accepted", desc: unknown, blob: blob, policy: AcceptUnknownAlgorithms, valid: true},
+		{name: "unknown accepted size mismatch", desc: unknown, blob: blob[1:], policy: AcceptUnknownAlgorithms},
+		{name: "unknown warned", desc: unknown, blob: blob, policy: warn, valid: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyDescriptor(tt.desc, bytes.NewReader(tt.blob), tt.policy)
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+	if len(warnings) != 1 || !errors.Is(warnings[0], digest.ErrDigestUnsupported) {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+}
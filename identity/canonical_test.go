@@ -0,0 +1,70 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{input: `{ "b": [1, 2, {"d": true, "c": null}], "a": "x" }`, expected: `{"a":"x","b":[1,2,{"c":null,"d":true}]}`},
+		// sorting by UTF-16 code units, from RFC 8785 section 3.2.3
+		{
+			input:    `{"\u20ac":"Euro Sign","\r":"Carriage Return","\ufb33":"Hebrew Letter Dalet With Dagesh","1":"One","\ud83d\ude00":"Emoji: Grinning Face","\u0080":"Control","\u00f6":"Latin Small Letter O With Diaeresis"}`,
+			expected: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{input: `"\u000f\u0008<>&\u2028\/\"\\"`, expected: "\"\\u000f\\b<>&\u2028/\\\"\\\\\""},
+		{input: `[1E2, 1.50, -0, 0.000001, 1e-7, 1e21, 999999999999999900000, 123456789012345680000, 5e-324, 1.7976931348623157e308, 9007199254740992]`,
+			expected: `[100,1.5,0,0.000001,1e-7,1e+21,999999999999999900000,123456789012345680000,5e-324,1.7976931348623157e+308,9007199254740992]`},
+	} {
+		var v interface{}
+		if err := json.Unmarshal([]byte(tt.input), &v); err != nil {
+			t.Fatal(err)
+		}
+		out, err := CanonicalJSON(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != tt.expected {
+			t.Errorf("unexpected canonical form of %s:\n%s\nexpected:\n%s", tt.input, out, tt.expected)
+		}
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	for _, tt := range []struct {
+		f        float64
+		expected string
+	}{
+		{0, "0"},
+		{math.Copysign(0, -1), "0"},
+		{-1, "-1"},
+		{1e20, "100000000000000000000"},
+		{1.5e-6, "0.0000015"},
+		{-1.5e-7, "-1.5e-7"},
+		{1.25e22, "1.25e+22"},
+		{333333333.3333332, "333333333.3333332"},
+	} {
+		if s := formatNumber(tt.f); s != tt.expected {
+			t.Errorf("unexpected format of %g: %s != %s", tt.f, s, tt.expected)
+		}
+	}
+}
@@ -21,21 +21,20 @@
 // directly.
 package identity
 
-import "github.com/opencontainers/go-digest"
+import (
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
 
 // ChainID takes a slice of digests and returns the ChainID corresponding to
 // the last entry. Typically, these are a list of layer DiffIDs, with the
 // result providing the ChainID identifying the result of sequential
 // application of the preceding layers.
 func ChainID(dgsts []digest.Digest) digest.Digest {
-	chainIDs := make([]digest.Digest, len(dgsts))
-	copy(chainIDs, dgsts)
-	ChainIDs(chainIDs)
-
-	if len(chainIDs) == 0 {
-		return ""
-	}
-	return chainIDs[len(chainIDs)-1]
+	c := &ChainBuilder{alg: digest.Canonical}
+	return c.Push(dgsts...)
 }
 
 // ChainIDs calculates the recursively applied chain id for each identifier in
@@ -53,15 +52,78 @@ func ChainID(dgsts []digest.Digest) digest.Digest {
 // Typically, these are a list of layer DiffIDs, with the
 // result providing the ChainID for each the result of each layer application
 // sequentially.
+//
+// Use a ChainBuilder to compute ChainIDs without modifying the input.
 func ChainIDs(dgsts []digest.Digest) []digest.Digest {
-	if len(dgsts) < 2 {
-		return dgsts
+	c := &ChainBuilder{alg: digest.Canonical}
+	for i, dgst := range dgsts {
+		dgsts[i] = c.Push(dgst)
 	}
+	return dgsts
+}
 
-	parent := digest.FromBytes([]byte(dgsts[0] + " " + dgsts[1]))
-	next := dgsts[1:]
-	next[0] = parent
-	ChainIDs(next)
+// ChainBuilder computes ChainIDs incrementally, as layers are applied one
+// after the other.
+type ChainBuilder struct {
+	alg digest.Algorithm
+	id  digest.Digest
+}
 
-	return dgsts
+// NewChainBuilder returns a ChainBuilder extending the chain identified by
+// parent, or starting a new chain if parent is empty. The ChainIDs of the
+// following layers are digested with alg, such as digest.SHA256,
+// digest.SHA512 or digest.BLAKE3. It returns an error wrapping
+// digest.ErrDigestUnsupported if alg is not available.
+func NewChainBuilder(alg digest.Algorithm, parent digest.Digest) (*ChainBuilder, error) {
+	if !alg.Available() {
+		return nil, fmt.Errorf("%w: %s", digest.ErrDigestUnsupported, alg)
+	}
+	return &ChainBuilder{alg: alg, id: parent}, nil
+}
+
+// Push applies the layers with the given DiffIDs, in order, and returns the
+// resulting ChainID.
+func (c *ChainBuilder) Push(diffIDs ...digest.Digest) digest.Digest {
+	for _, diffID := range diffIDs {
+		if c.id == "" {
+			c.id = diffID
+			continue
+		}
+		c.id = c.alg.FromString(c.id.String() + " " + diffID.String())
+	}
+	return c.id
+}
+
+// ChainID returns the ChainID of the layers applied so far, or the parent
+// if none were.
+func (c *ChainBuilder) ChainID() digest.Digest {
+	return c.id
+}
+
+// ImageChainIDs returns the ChainID of each layer of img, in the order of
+// its DiffIDs. The ChainIDs are digested with the algorithm of the first
+// DiffID.
+func ImageChainIDs(img v1.Image) ([]digest.Digest, error) {
+	if img.RootFS.Type != "layers" {
+		return nil, fmt.Errorf("unsupported rootfs type %q", img.RootFS.Type)
+	}
+	diffIDs := img.RootFS.DiffIDs
+	if len(diffIDs) == 0 {
+		return nil, nil
+	}
+	for _, diffID := range diffIDs {
+		if err := diffID.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid diff_id %q: %w", diffID, err)
+		}
+	}
+
+	c, err := NewChainBuilder(diffIDs[0].Algorithm(), "")
+	if err != nil {
+		return nil, err
+	}
+	chainIDs := make([]digest.Digest, len(diffIDs))
+	for i, diffID := range diffIDs {
+		chainIDs[i] = c.Push(diffID)
+	}
+	return chainIDs, nil
 }
@@ -16,10 +16,12 @@ package identity
 
 import (
 	_ "crypto/sha256" // required to install sha256 digest support
+	"errors"
 	"reflect"
 	"testing"
 
 	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 func TestChainID(t *testing.T) {
@@ -93,3 +95,74 @@ func TestChainID(t *testing.T) {
 		})
 	}
 }
+
+func TestChainBuilder(t *testing.T) {
+	diffIDs := []digest.Digest{"sha256:a", "sha256:b", "sha256:c"}
+	input := append([]digest.Digest(nil), diffIDs...)
+	expected := ChainIDs(append([]digest.Digest(nil), diffIDs...))
+
+	newChainBuilder := func(alg digest.Algorithm, parent digest.Digest) *ChainBuilder {
+		t.Helper()
+		c, err := NewChainBuilder(alg, parent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	c := newChainBuilder(digest.SHA256, "")
+	if id := c.ChainID(); id != "" {
+		t.Errorf("unexpected ChainID of an empty chain: %v", id)
+	}
+	for i, diffID := range diffIDs {
+		if id := c.Push(diffID); id != expected[i] || c.ChainID() != id {
+			t.Errorf("unexpected ChainID %d: %v != %v", i, id, expected[i])
+		}
+	}
+	if !reflect.DeepEqual(diffIDs, input) {
+		t.Errorf("input was modified: %v", diffIDs)
+	}
+
+	// extending a chain gives the same result as computing it at once
+	parent := newChainBuilder(digest.SHA256, "").Push(diffIDs[:2]...)
+	if id := newChainBuilder(digest.SHA256, parent).Push(diffIDs[2]); id != expected[2] {
+		t.Errorf("unexpected extended ChainID: %v != %v", id, expected[2])
+	}
+
+	sha512 := newChainBuilder(digest.SHA512, "").Push(diffIDs...)
+	if expected := digest.SHA512.FromString(digest.SHA512.FromString("sha256:a sha256:b").String() + " sha256:c"); sha512 != expected {
+		t.Errorf("unexpected sha512 ChainID: %v != %v", sha512, expected)
+	}
+
+	if _, err := NewChainBuilder("unknown", ""); !errors.Is(err, digest.ErrDigestUnsupported) {
+		t.Errorf("unexpected error for an unavailable algorithm: %v", err)
+	}
+}
+
+func TestImageChainIDs(t *testing.T) {
+	var diffIDs []digest.Digest
+	for _, alg := range []digest.Algorithm{digest.SHA512, digest.BLAKE3} {
+		diffIDs = []digest.Digest{alg.FromString("a"), alg.FromString("b")}
+		img := v1.Image{RootFS: v1.RootFS{Type: "layers", DiffIDs: diffIDs}}
+		chainIDs, err := ImageChainIDs(img)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []digest.Digest{diffIDs[0], alg.FromString(diffIDs[0].String() + " " + diffIDs[1].String())}
+		if !reflect.DeepEqual(chainIDs, expected) {
+			t.Errorf("unexpected %s ChainIDs: %v", alg, chainIDs)
+		}
+		if img.RootFS.DiffIDs[1] != diffIDs[1] {
+			t.Error("image DiffIDs were modified")
+		}
+	}
+
+	for _, invalid := range []v1.Image{
+		{RootFS: v1.RootFS{Type: "other", DiffIDs: diffIDs}},
+		{RootFS: v1.RootFS{Type: "layers", DiffIDs: []digest.Digest{"sha256:a"}}},
+		{RootFS: v1.RootFS{Type: "layers", DiffIDs: []digest.Digest{"unknown:0123456789abcdef"}}},
+	} {
+		if _, err := ImageChainIDs(invalid); err == nil {
+			t.Errorf("expected %v to be invalid", invalid.RootFS)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/content"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ErrInconsistent is wrapped by the error returned by Report.Err.
+var ErrInconsistent = errors.New("inconsistent image")
+
+// LayerReport is the result of checking a layer of a manifest.
+type LayerReport struct {
+	// Descriptor is the descriptor of the layer in the manifest.
+	Descriptor v1.Descriptor
+
+	// Skipped is set for layers that are not filesystem changesets, such as
+	// the empty descriptor or artifact blobs, which have no diff_id.
+	Skipped bool
+
+	// DiffID is the diff_id of the configuration corresponding to the
+	// layer, or empty if there is none.
+	DiffID digest.Digest
+
+	// Layer is the identity of the layer as computed from its content, or
+	// the zero value if the content could not be read.
+	Layer Layer
+
+	// Err is the reason why the layer does not match the configuration, or
+	// nil if it does.
+	Err error
+}
+
+// Report is the result of CheckImage.
+type Report struct {
+	// Layers has a report for each layer of the manifest, in order.
+	Layers []LayerReport
+
+	// Errors are the inconsistencies that are not specific to a layer.
+	Errors []error
+}
+
+// Err returns an error listing every inconsistency of the report, or nil if
+// there are none.
+func (r Report) Err() error {
+	var msgs []string
+	for _, err := range r.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	for i, l := range r.Layers {
+		if l.Err != nil {
+			msgs = append(msgs, fmt.Sprintf("layer %d: %v", i, l.Err))
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInconsistent, strings.Join(msgs, "; "))
+}
+
+// CheckImage checks that the layers of manifest correspond to the diff_ids
+// of its configuration config: filesystem layers and diff_ids are paired in
+// order, and each layer, fetched from f, must decompress to its diff_id.
+// When the configuration has a history, its entries that are not marked as
+// empty_layer must also pair with the layers.
+//
+// Inconsistencies are reported in the returned Report. An error is returned
+// only if config is not the configuration of manifest.
+func CheckImage(ctx context.Context, f content.Fetcher, manifest v1.Manifest, config []byte) (Report, error) {
+	img, err := parseConfig(manifest.Config, config)
+	if err != nil {
+		return Report{}, err
+	}
+	diffIDs := img.RootFS.DiffIDs
+
+	var (
+		report Report
+		n      int // filesystem layers
+	)
+	for _, desc := range manifest.Layers {
+		l := LayerReport{Descriptor: desc}
+		if !isFilesystemLayer(desc.MediaType) {
+			l.Skipped = true
+			report.Layers = append(report.Layers, l)
+			continue
+		}
+		if n < len(diffIDs) {
+			l.DiffID = diffIDs[n]
+		}
+		n++
+
+		l.Layer, l.Err = layerIdentity(ctx, f, desc)
+		switch {
+		case l.Err != nil:
+		case l.DiffID == "":
+			l.Err = fmt.Errorf("%s has no diff_id", desc.Digest)
+		case l.Layer.DiffID != l.DiffID:
+			l.Err = fmt.Errorf("%s decompresses to %s, not diff_id %s", desc.Digest, l.Layer.DiffID, l.DiffID)
+			for i, diffID := range diffIDs {
+				if diffID == l.Layer.DiffID {
+					l.Err = fmt.Errorf("%w, which is diff_id %d", l.Err, i)
+					break
+				}
+			}
+		}
+		report.Layers = append(report.Layers, l)
+	}
+	for i := n; i < len(diffIDs); i++ {
+		report.Errors = append(report.Errors, fmt.Errorf("diff_id %d %s has no layer", i, diffIDs[i]))
+	}
+
+	if len(img.History) > 0 {
+		var empty []int
+		nonEmpty := 0
+		for i, h := range img.History {
+			if h.EmptyLayer {
+				empty = append(empty, i)
+			} else {
+				nonEmpty++
+			}
+		}
+		switch {
+		case nonEmpty < n && len(empty) > 0:
+			for _, i := range empty {
+				report.Errors = append(report.Errors, fmt.Errorf("history entry %d is marked empty_layer, but there are %d layers for %d non-empty history entries", i, n, nonEmpty))
+			}
+		case nonEmpty != n:
+			report.Errors = append(report.Errors, fmt.Errorf("history has %d non-empty entries for %d layers", nonEmpty, n))
+		}
+	}
+	return report, nil
+}
+
+func layerIdentity(ctx context.Context, f content.Fetcher, desc v1.Descriptor) (Layer, error) {
+	rc, err := f.Fetch(ctx, desc)
+	if err != nil {
+		return Layer{}, err
+	}
+	defer rc.Close()
+	return DiffID(desc, rc)
+}
+
+// isFilesystemLayer returns whether mediaType is the type of a filesystem
+// changeset, including the deprecated non-distributable types and the Docker
+// types.
+func isFilesystemLayer(mediaType string) bool {
+	base, _, _ := strings.Cut(mediaType, "+")
+	switch base {
+	case v1.MediaTypeImageLayer, "application/vnd.oci.image.layer.nondistributable.v1.tar":
+		return true
+	}
+	return strings.HasPrefix(mediaType, "application/vnd.docker.image.rootfs.diff.tar") ||
+		strings.HasPrefix(mediaType, "application/vnd.docker.image.rootfs.foreign.diff.tar")
+}
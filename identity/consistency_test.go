@@ -0,0 +1,160 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/compression"
+	"github.com/opencontainers/image-spec/content"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type memoryFetcher map[digest.Digest][]byte
+
+func (f memoryFetcher) Fetch(_ context.Context, desc v1.Descriptor) (io.ReadCloser, error) {
+	b, ok := f[desc.Digest]
+	if !ok {
+		return nil, content.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func TestCheckImage(t *testing.T) {
+	blobs := memoryFetcher{}
+	var (
+		descs   []v1.Descriptor
+		diffIDs []digest.Digest
+	)
+	for _, c := range []string{"first", "second", "third"} {
+		blob := compressed(t, compression.Gzip, []byte(c))
+		blobs[digest.FromBytes(blob)] = blob
+		descs = append(descs, v1.Descriptor{MediaType: v1.MediaTypeImageLayerGzip, Digest: digest.FromBytes(blob), Size: int64(len(blob))})
+		diffIDs = append(diffIDs, digest.FromString(c))
+	}
+	blobs[v1.DescriptorEmptyJSON.Digest] = []byte("{}")
+
+	check := func(layers []v1.Descriptor, img v1.Image) Report {
+		t.Helper()
+		img.RootFS.Type = "layers"
+		config, err := json.Marshal(img)
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest := v1.Manifest{
+			Config: v1.Descriptor{MediaType: v1.MediaTypeImageConfig, Digest: digest.FromBytes(config), Size: int64(len(config))},
+			Layers: layers,
+		}
+		report, err := CheckImage(context.Background(), blobs, manifest, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Layers) != len(layers) {
+			t.Fatalf("unexpected number of layer reports: %d", len(report.Layers))
+		}
+		return report
+	}
+	layerErrors := func(r Report) []bool {
+		var errs []bool
+		for _, l := range r.Layers {
+			errs = append(errs, l.Err != nil)
+		}
+		return errs
+	}
+
+	t.Run("consistent", func(t *testing.T) {
+		r := check(append(append([]v1.Descriptor{}, descs...), v1.DescriptorEmptyJSON), v1.Image{
+			RootFS: v1.RootFS{DiffIDs: diffIDs},
+			History: []v1.History{
+				{CreatedBy: "ADD first"},
+				{CreatedBy: "ENV A=b", EmptyLayer: true},
+				{CreatedBy: "ADD second"},
+				{CreatedBy: "ADD third"},
+			},
+		})
+		if err := r.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if !r.Layers[3].Skipped || r.Layers[0].Skipped {
+			t.Errorf("unexpected skipped layers: %+v", r.Layers)
+		}
+		if r.Layers[1].Layer.DiffID != diffIDs[1] || r.Layers[1].DiffID != diffIDs[1] {
+			t.Errorf("unexpected layer report: %+v", r.Layers[1])
+		}
+	})
+
+	t.Run("out of order", func(t *testing.T) {
+		r := check([]v1.Descriptor{descs[1], descs[0], descs[2]}, v1.Image{RootFS: v1.RootFS{DiffIDs: diffIDs}})
+		if got := layerErrors(r); got[0] != true || got[1] != true || got[2] != false {
+			t.Errorf("unexpected layer errors: %v", r.Err())
+		}
+		if !strings.Contains(r.Layers[0].Err.Error(), "which is diff_id 1") {
+			t.Errorf("unexpected error: %v", r.Layers[0].Err)
+		}
+		if !errors.Is(r.Err(), ErrInconsistent) {
+			t.Errorf("unexpected error: %v", r.Err())
+		}
+	})
+
+	t.Run("count mismatch", func(t *testing.T) {
+		r := check(descs[:2], v1.Image{RootFS: v1.RootFS{DiffIDs: diffIDs}})
+		if len(r.Errors) != 1 || r.Layers[0].Err != nil || r.Layers[1].Err != nil {
+			t.Errorf("unexpected report: %v", r.Err())
+		}
+		r = check(descs, v1.Image{RootFS: v1.RootFS{DiffIDs: diffIDs[:2]}})
+		if got := layerErrors(r); got[2] != true || len(r.Errors) != 0 {
+			t.Errorf("unexpected report: %v", r.Err())
+		}
+	})
+
+	t.Run("missing blob", func(t *testing.T) {
+		missing := v1.Descriptor{MediaType: v1.MediaTypeImageLayer, Digest: digest.FromString("missing"), Size: 7}
+		r := check([]v1.Descriptor{descs[0], missing}, v1.Image{RootFS: v1.RootFS{DiffIDs: []digest.Digest{diffIDs[0], missing.Digest}}})
+		if !errors.Is(r.Layers[1].Err, content.ErrNotFound) || r.Layers[0].Err != nil {
+			t.Errorf("unexpected report: %v", r.Err())
+		}
+	})
+
+	t.Run("empty layer present", func(t *testing.T) {
+		r := check(descs, v1.Image{
+			RootFS: v1.RootFS{DiffIDs: diffIDs},
+			History: []v1.History{
+				{CreatedBy: "ADD first"},
+				{CreatedBy: "ADD second", EmptyLayer: true},
+				{CreatedBy: "ADD third"},
+			},
+		})
+		if len(r.Errors) != 1 || !strings.Contains(r.Errors[0].Error(), "history entry 1 is marked empty_layer") {
+			t.Errorf("unexpected report: %v", r.Err())
+		}
+	})
+
+	t.Run("history mismatch", func(t *testing.T) {
+		r := check(descs[:1], v1.Image{
+			RootFS:  v1.RootFS{DiffIDs: diffIDs[:1]},
+			History: []v1.History{{CreatedBy: "ADD first"}, {CreatedBy: "ADD second"}},
+		})
+		if len(r.Errors) != 1 {
+			t.Errorf("unexpected report: %v", r.Err())
+		}
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/compression"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Layer is the identity of a layer blob.
+type Layer struct {
+	// Digest is the digest of the blob, as in its descriptor.
+	Digest digest.Digest
+
+	// Size is the size of the blob, as in its descriptor.
+	Size int64
+
+	// DiffID is the digest of the uncompressed content of the blob, as in
+	// the diff_ids of the image configuration.
+	DiffID digest.Digest
+
+	// UncompressedSize is the size of the uncompressed content of the blob.
+	UncompressedSize int64
+}
+
+// DiffID reads the layer blob described by desc from r, and returns its
+// identity once verified against desc. The blob is decompressed according to
+// the media type of desc, as detected by compression.FromMediaType, and
+// digested with digest.Canonical, in a single pass.
+func DiffID(desc v1.Descriptor, r io.Reader) (Layer, error) {
+	if err := desc.Digest.Validate(); err != nil {
+		return Layer{}, err
+	}
+	comp := compression.FromMediaType(desc.MediaType)
+
+	verifier := desc.Digest.Verifier()
+	compressed := &countingReader{r: io.TeeReader(io.LimitReader(r, desc.Size+1), verifier)}
+	dr, err := comp.Decompress(compressed)
+	if err != nil {
+		return Layer{}, fmt.Errorf("layer %s: %w", desc.Digest, err)
+	}
+	defer dr.Close()
+
+	digester := digest.Canonical.Digester()
+	uncompressed, err := io.Copy(digester.Hash(), dr)
+	if err != nil {
+		return Layer{}, fmt.Errorf("layer %s: decompressing %s: %w", desc.Digest, comp, err)
+	}
+	// trailing data after the compressed stream is part of the blob
+	if _, err := io.Copy(io.Discard, compressed); err != nil {
+		return Layer{}, fmt.Errorf("layer %s: %w", desc.Digest, err)
+	}
+	if compressed.n != desc.Size {
+		return Layer{}, fmt.Errorf("layer %s: unexpected size", desc.Digest)
+	}
+	if !verifier.Verified() {
+		return Layer{}, fmt.Errorf("layer %s: content does not match its digest", desc.Digest)
+	}
+
+	return Layer{
+		Digest:           desc.Digest,
+		Size:             desc.Size,
+		DiffID:           digester.Digest(),
+		UncompressedSize: uncompressed,
+	}, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/compression"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func compressed(t *testing.T, alg compression.Algorithm, p []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := alg.Compress(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDiffID(t *testing.T) {
+	tar := bytes.Repeat([]byte("uncompressed layer content\n"), 1000)
+	diffID := digest.FromBytes(tar)
+	gz := compressed(t, compression.Gzip, tar)
+	zst := compressed(t, compression.Zstd, tar)
+	describe := func(mediaType string, blob []byte) v1.Descriptor {
+		return v1.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(blob), Size: int64(len(blob))}
+	}
+
+	for _, tt := range []struct {
+		name string
+		desc v1.Descriptor
+		blob []byte
+		fail bool
+	}{
+		{name: "uncompressed", desc: describe(v1.MediaTypeImageLayer, tar), blob: tar},
+		{name: "gzip", desc: describe(v1.MediaTypeImageLayerGzip, gz), blob: gz},
+		{name: "zstd", desc: describe(v1.MediaTypeImageLayerZstd, zst), blob: zst},
+		{name: "truncated zstd", desc: describe(v1.MediaTypeImageLayerZstd, zst), blob: zst[:len(zst)-1], fail: true},
+		{name: "sha512", desc: v1.Descriptor{MediaType: v1.MediaTypeImageLayerGzip, Digest: digest.SHA512.FromBytes(gz), Size: int64(len(gz))}, blob: gz},
+		{name: "truncated", desc: describe(v1.MediaTypeImageLayerGzip, gz), blob: gz[:len(gz)-1], fail: true},
+		{name: "trailing data", desc: describe(v1.MediaTypeImageLayer, tar), blob: append(append([]byte{}, tar...), '!'), fail: true},
+		{name: "corrupt", desc: describe(v1.MediaTypeImageLayer, tar), blob: bytes.ToUpper(tar), fail: true},
+		{name: "wrong size", desc: v1.Descriptor{MediaType: v1.MediaTypeImageLayerGzip, Digest: digest.FromBytes(gz), Size: int64(len(gz)) + 1}, blob: gz, fail: true},
+		{name: "not compressed", desc: describe(v1.MediaTypeImageLayerGzip, tar), blob: tar, fail: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			layer, err := DiffID(tt.desc, bytes.NewReader(tt.blob))
+			if tt.fail {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", layer)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := Layer{Digest: tt.desc.Digest, Size: tt.desc.Size, DiffID: diffID, UncompressedSize: int64(len(tar))}
+			if layer != expected {
+				t.Errorf("unexpected layer: %+v", layer)
+			}
+		})
+	}
+}
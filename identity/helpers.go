@@ -18,9 +18,11 @@ import (
 	_ "crypto/sha256" // side-effect to install impls, sha256
 	_ "crypto/sha512" // side-effect to install impls, sha384/sh512
 
+	"fmt"
 	"io"
 
 	digest "github.com/opencontainers/go-digest"
+	_ "github.com/opencontainers/go-digest/blake3" // side-effect to install impls, blake3
 )
 
 // FromReader consumes the content of rd until io.EOF, returning canonical
@@ -38,3 +40,20 @@ func FromBytes(p []byte) digest.Digest {
 func FromString(s string) digest.Digest {
 	return digest.Canonical.FromString(s)
 }
+
+// FromReaderWith consumes the content of rd until io.EOF, returning its
+// digest with the algorithm alg, such as digest.BLAKE3.
+func FromReaderWith(alg digest.Algorithm, rd io.Reader) (digest.Digest, error) {
+	if !alg.Available() {
+		return "", fmt.Errorf("%w: %s", digest.ErrDigestUnsupported, alg)
+	}
+	return alg.FromReader(rd)
+}
+
+// FromBytesWith digests the input with the algorithm alg and returns a Digest.
+func FromBytesWith(alg digest.Algorithm, p []byte) (digest.Digest, error) {
+	if !alg.Available() {
+		return "", fmt.Errorf("%w: %s", digest.ErrDigestUnsupported, alg)
+	}
+	return alg.FromBytes(p), nil
+}
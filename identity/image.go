@@ -0,0 +1,96 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ImageID returns the ImageID of img, which is the SHA256 digest of its
+// configuration JSON, serialized with CanonicalJSON.
+//
+// The ImageID of an existing image must be computed from its configuration
+// as stored, with ImageIDFromConfig, as other serializations give other IDs.
+func ImageID(img v1.Image) (digest.Digest, error) {
+	config, err := CanonicalJSON(img)
+	if err != nil {
+		return "", err
+	}
+	return ImageIDFromConfig(config), nil
+}
+
+// ImageIDFromConfig returns the ImageID of the image with the serialized
+// configuration config.
+func ImageIDFromConfig(config []byte) digest.Digest {
+	return digest.SHA256.FromBytes(config)
+}
+
+// ManifestDigest returns the canonical digest of m, serialized with
+// CanonicalJSON.
+func ManifestDigest(m v1.Manifest) (digest.Digest, error) {
+	raw, err := CanonicalJSON(m)
+	if err != nil {
+		return "", err
+	}
+	return digest.Canonical.FromBytes(raw), nil
+}
+
+// VerifyImage checks that config is the configuration referenced by
+// manifest, and that layers, the identities of the layers of manifest in
+// order as returned by DiffID, match the layers of the configuration.
+func VerifyImage(manifest v1.Manifest, config []byte, layers []Layer) error {
+	img, err := parseConfig(manifest.Config, config)
+	if err != nil {
+		return err
+	}
+	if len(layers) != len(manifest.Layers) {
+		return fmt.Errorf("got %d layer identities for %d manifest layers", len(layers), len(manifest.Layers))
+	}
+	if len(manifest.Layers) != len(img.RootFS.DiffIDs) {
+		return fmt.Errorf("manifest has %d layers but config has %d diff_ids", len(manifest.Layers), len(img.RootFS.DiffIDs))
+	}
+	for i, layer := range layers {
+		if layer.Digest != manifest.Layers[i].Digest {
+			return fmt.Errorf("layer %d: identity of %s given for manifest layer %s", i, layer.Digest, manifest.Layers[i].Digest)
+		}
+		if layer.DiffID != img.RootFS.DiffIDs[i] {
+			return fmt.Errorf("layer %d: DiffID %s of %s does not match diff_id %s", i, layer.DiffID, layer.Digest, img.RootFS.DiffIDs[i])
+		}
+	}
+	return nil
+}
+
+// parseConfig checks that config is the content described by desc, and
+// parses it.
+func parseConfig(desc v1.Descriptor, config []byte) (v1.Image, error) {
+	if err := desc.Digest.Validate(); err != nil {
+		return v1.Image{}, fmt.Errorf("config descriptor: %w", err)
+	}
+	if int64(len(config)) != desc.Size {
+		return v1.Image{}, fmt.Errorf("config size %d does not match descriptor size %d", len(config), desc.Size)
+	}
+	if desc.Digest.Algorithm().FromBytes(config) != desc.Digest {
+		return v1.Image{}, fmt.Errorf("config does not match descriptor digest %s", desc.Digest)
+	}
+	var img v1.Image
+	if err := json.Unmarshal(config, &img); err != nil {
+		return v1.Image{}, fmt.Errorf("parsing config %s: %w", desc.Digest, err)
+	}
+	return img, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/compression"
+	"github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type testImage struct {
+	manifest v1.Manifest
+	config   []byte
+	layers   []Layer
+}
+
+func newTestImage(t *testing.T) testImage {
+	t.Helper()
+	var (
+		img    = v1.Image{Platform: v1.Platform{OS: "linux", Architecture: "amd64"}, RootFS: v1.RootFS{Type: "layers"}}
+		descs  []v1.Descriptor
+		layers []Layer
+	)
+	for _, content := range []string{"first layer", "second layer"} {
+		blob := compressed(t, compression.Gzip, []byte(content))
+		desc := v1.Descriptor{MediaType: v1.MediaTypeImageLayerGzip, Digest: digest.FromBytes(blob), Size: int64(len(blob))}
+		layer, err := DiffID(desc, bytes.NewReader(blob))
+		if err != nil {
+			t.Fatal(err)
+		}
+		img.RootFS.DiffIDs = append(img.RootFS.DiffIDs, layer.DiffID)
+		descs = append(descs, desc)
+		layers = append(layers, layer)
+	}
+	config, err := json.Marshal(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testImage{
+		manifest: v1.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: v1.MediaTypeImageManifest,
+			Config:    v1.Descriptor{MediaType: v1.MediaTypeImageConfig, Digest: digest.FromBytes(config), Size: int64(len(config))},
+			Layers:    descs,
+		},
+		config: config,
+		layers: layers,
+	}
+}
+
+func TestImageID(t *testing.T) {
+	config := []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`)
+	if id := ImageIDFromConfig(config); id != digest.SHA256.FromBytes(config) {
+		t.Errorf("unexpected ImageID: %s", id)
+	}
+
+	var img v1.Image
+	if err := json.Unmarshal(config, &img); err != nil {
+		t.Fatal(err)
+	}
+	id, err := ImageID(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical := `{"architecture":"amd64","os":"linux","rootfs":{"diff_ids":[],"type":"layers"}}`
+	if expected := digest.SHA256.FromString(canonical); id != expected {
+		t.Errorf("unexpected ImageID: %s != %s", id, expected)
+	}
+}
+
+func TestManifestDigest(t *testing.T) {
+	img := newTestImage(t)
+	dgst, err := ManifestDigest(img.manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := CanonicalJSON(img.manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dgst != digest.FromBytes(canonical) {
+		t.Errorf("unexpected digest: %s", dgst)
+	}
+	if !bytes.HasPrefix(canonical, []byte(`{"config":{"digest":"sha256:`)) {
+		t.Errorf("unexpected canonical manifest: %s", canonical)
+	}
+}
+
+func TestVerifyImage(t *testing.T) {
+	img := newTestImage(t)
+	if err := VerifyImage(img.manifest, img.config, img.layers); err != nil {
+		t.Fatal(err)
+	}
+
+	swapped := []Layer{img.layers[1], img.layers[0]}
+	wrongDiffID := append([]Layer{}, img.layers...)
+	wrongDiffID[1].DiffID = digest.FromString("other")
+	fewerLayers := img.manifest
+	fewerLayers.Layers = fewerLayers.Layers[:1]
+
+	for _, tt := range []struct {
+		name     string
+		manifest v1.Manifest
+		config   []byte
+		layers   []Layer
+	}{
+		{name: "config mismatch", manifest: img.manifest, config: append([]byte(" "), img.config[1:]...), layers: img.layers},
+		{name: "config size", manifest: img.manifest, config: append(img.config, ' '), layers: img.layers},
+		{name: "swapped layers", manifest: img.manifest, config: img.config, layers: swapped},
+		{name: "wrong DiffID", manifest: img.manifest, config: img.config, layers: wrongDiffID},
+		{name: "missing layer identity", manifest: img.manifest, config: img.config, layers: img.layers[:1]},
+		{name: "missing diff_id", manifest: fewerLayers, config: img.config, layers: img.layers[:1]},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyImage(tt.manifest, tt.config, tt.layers); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/compression"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Export writes the layout of r to w as a tar archive, compressed with alg,
+// such as compression.None or compression.Gzip.
+//
+// The archive starts with oci-layout and index.json, followed by the blobs
+// sorted by path, with fixed ownership, permissions and times, such that
+// layouts with the same content give identical archives. Blobs are verified
+// as they are written.
+func Export(ctx context.Context, w io.Writer, r *Reader, alg compression.Algorithm) error {
+	cw, err := alg.Compress(w)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(cw)
+
+	for _, name := range []string{v1.ImageLayoutFile, v1.ImageIndexFile} {
+		raw, err := readFile(r.fsys, name)
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(archiveHeader(name, int64(len(raw)))); err != nil {
+			return err
+		}
+		if _, err := tw.Write(raw); err != nil {
+			return err
+		}
+	}
+
+	blobs, err := r.blobs()
+	if err != nil {
+		return err
+	}
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].path < blobs[j].path })
+	if err := tw.WriteHeader(archiveDirHeader(v1.ImageBlobsDir)); err != nil {
+		return err
+	}
+	dir := ""
+	for _, blob := range blobs {
+		if d := path.Dir(blob.path); d != dir {
+			if err := tw.WriteHeader(archiveDirHeader(d)); err != nil {
+				return err
+			}
+			dir = d
+		}
+		if err := exportBlob(ctx, tw, r, blob); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return cw.Close()
+}
+
+func exportBlob(ctx context.Context, tw *tar.Writer, r *Reader, blob blobFile) error {
+	rc, err := r.Fetch(ctx, v1.Descriptor{Digest: blob.Digest, Size: blob.Size})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := tw.WriteHeader(archiveHeader(blob.path, blob.Size)); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, rc)
+	return err
+}
+
+func archiveHeader(name string, size int64) *tar.Header {
+	return &tar.Header{Typeflag: tar.TypeReg, Name: name, Size: size, Mode: 0o644, ModTime: time.Unix(0, 0)}
+}
+
+func archiveDirHeader(name string) *tar.Header {
+	return &tar.Header{Typeflag: tar.TypeDir, Name: name + "/", Mode: 0o755, ModTime: time.Unix(0, 0)}
+}
+
+// Import reads a layout from the tar archive src, compressed with any
+// available algorithm, into the layout of dst, and returns the index of the
+// archive.
+//
+// Blobs are pushed to dst, and thus verified, as they are read. The entries
+// of the index of the archive are then added to the index of dst, replacing
+// the entries with the same AnnotationRefName. If the archive is invalid,
+// the blobs it added to dst are removed.
+//
+// Other files of the archive, such as the manifest.json of archives also
+// loadable by docker, are ignored as image-layout.md requires. Files of the
+// blobs directory must be blobs.
+func Import(ctx context.Context, dst *Writer, src io.Reader) (_ v1.Index, err error) {
+	br := bufio.NewReader(src)
+	header, _ := br.Peek(4)
+	rc, err := compression.Detect(header).Decompress(br)
+	if err != nil {
+		return v1.Index{}, err
+	}
+	defer rc.Close()
+
+	var (
+		tr         = tar.NewReader(rc)
+		hasIndex   bool
+		hasVersion bool
+		index      v1.Index
+		pushed     []digest.Digest // the blobs not in dst before
+	)
+	defer func() {
+		if err != nil {
+			for _, d := range pushed {
+				dst.removeBlob(d)
+			}
+		}
+	}()
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return v1.Index{}, err
+		}
+		name := path.Clean(hdr.Name)
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return v1.Index{}, fmt.Errorf("unexpected archive entry %s of type %q", hdr.Name, hdr.Typeflag)
+		}
+
+		switch {
+		case name == v1.ImageLayoutFile:
+			raw, err := readArchiveFile(tr, name)
+			if err != nil {
+				return v1.Index{}, err
+			}
+			var layout v1.ImageLayout
+			if err := json.Unmarshal(raw, &layout); err != nil {
+				return v1.Index{}, fmt.Errorf("parsing %s: %w", v1.ImageLayoutFile, err)
+			}
+			if layout.Version != v1.ImageLayoutVersion {
+				return v1.Index{}, fmt.Errorf("%w: %q", ErrUnsupportedVersion, layout.Version)
+			}
+			hasVersion = true
+		case name == v1.ImageIndexFile:
+			raw, err := readArchiveFile(tr, name)
+			if err != nil {
+				return v1.Index{}, err
+			}
+			index, err = parseIndex(raw)
+			if err != nil {
+				return v1.Index{}, err
+			}
+			for _, desc := range index.Manifests {
+				if err := checkRefName(desc); err != nil {
+					return v1.Index{}, err
+				}
+			}
+			hasIndex = true
+		case strings.HasPrefix(name, v1.ImageBlobsDir+"/"):
+			alg, encoded, _ := strings.Cut(strings.TrimPrefix(name, v1.ImageBlobsDir+"/"), "/")
+			d := digest.NewDigestFromEncoded(digest.Algorithm(alg), encoded)
+			if p, err := BlobPath(d); err != nil || p != name {
+				return v1.Index{}, fmt.Errorf("unexpected archive entry %s", hdr.Name)
+			}
+			desc := v1.Descriptor{Digest: d, Size: hdr.Size}
+			exists, err := dst.Reader.Exists(ctx, desc)
+			if err != nil {
+				return v1.Index{}, err
+			}
+			if err := dst.Push(ctx, desc, tr); err != nil {
+				return v1.Index{}, fmt.Errorf("importing %s: %w", hdr.Name, err)
+			}
+			if !exists {
+				pushed = append(pushed, d)
+			}
+		case name == ".." || strings.HasPrefix(name, "../") || path.IsAbs(name):
+			return v1.Index{}, fmt.Errorf("unexpected archive entry %s", hdr.Name)
+		}
+	}
+
+	if !hasVersion {
+		return v1.Index{}, fmt.Errorf("archive has no %s", v1.ImageLayoutFile)
+	}
+	if !hasIndex {
+		return v1.Index{}, fmt.Errorf("archive has no %s", v1.ImageIndexFile)
+	}
+	err = dst.UpdateIndex(func(dstIndex *v1.Index) error {
+		for _, desc := range index.Manifests {
+			if err := dst.checkBlob(desc); err != nil {
+				return err
+			}
+			if refName, ok := desc.Annotations[v1.AnnotationRefName]; ok {
+				dstIndex.Manifests = removeRef(dstIndex.Manifests, refName)
+			}
+			if !containsDescriptor(dstIndex.Manifests, desc) {
+				dstIndex.Manifests = append(dstIndex.Manifests, desc)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return v1.Index{}, err
+	}
+	return index, nil
+}
+
+func readArchiveFile(r io.Reader, name string) ([]byte, error) {
+	raw, err := io.ReadAll(io.LimitReader(r, maxIndexSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	if len(raw) > maxIndexSize {
+		return nil, fmt.Errorf("%s is too large", name)
+	}
+	return raw, nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/builder"
+	"github.com/opencontainers/image-spec/compression"
+	"github.com/opencontainers/image-spec/content"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// newArchiveLayout returns a layout with an image tagged "latest", pushing
+// its blobs in the given order.
+func newArchiveLayout(t *testing.T, reverse bool) (*Writer, v1.Descriptor) {
+	t.Helper()
+	w, err := Create(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs := [][]byte{
+		[]byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`),
+		[]byte("first layer"),
+		[]byte("second layer"),
+	}
+	if reverse {
+		blobs[0], blobs[2] = blobs[2], blobs[0]
+	}
+	descs := map[string]v1.Descriptor{}
+	for _, blob := range blobs {
+		descs[string(blob)] = pushBlob(t, w, v1.MediaTypeImageLayer, blob)
+	}
+	config := descs[`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`]
+	config.MediaType = v1.MediaTypeImageConfig
+	m := builder.NewManifest().Validate(builder.NoValidation).ConfigDescriptor(config).
+		LayerDescriptor(descs["first layer"]).
+		LayerDescriptor(descs["second layer"])
+	image := pushManifest(t, w, m)
+	if err := w.Tag(context.Background(), image, "latest"); err != nil {
+		t.Fatal(err)
+	}
+	return w, image
+}
+
+func export(t *testing.T, w *Writer, alg compression.Algorithm) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Export(context.Background(), &buf, w.Reader, alg); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	src, image := newArchiveLayout(t, false)
+	archive := export(t, src, compression.None)
+
+	// the archive does not depend on the order blobs were pushed in
+	other, _ := newArchiveLayout(t, true)
+	if !bytes.Equal(archive, export(t, other, compression.None)) {
+		t.Error("archives of identical layouts differ")
+	}
+
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+	}
+	if len(names) != 8 || names[0] != v1.ImageLayoutFile || names[1] != v1.ImageIndexFile || names[2] != "blobs/" || names[3] != "blobs/sha256/" {
+		t.Errorf("unexpected archive entries: %v", names)
+	}
+
+	for _, alg := range []compression.Algorithm{compression.None, compression.Gzip} {
+		t.Run(alg.String(), func(t *testing.T) {
+			dst, err := Create(t.TempDir(), Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			index, err := Import(ctx, dst, bytes.NewReader(export(t, src, alg)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(index.Manifests) != 1 {
+				t.Errorf("unexpected imported index: %v", index)
+			}
+			desc, err := dst.Resolve(ctx, "latest")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if desc.Digest != image.Digest {
+				t.Errorf("unexpected imported image %s", desc.Digest)
+			}
+			if !bytes.Equal(export(t, dst, compression.None), archive) {
+				t.Error("imported layout differs")
+			}
+			checkNoTempFiles(t, dst.Dir())
+		})
+	}
+}
+
+// tarFile is an entry of an archive built by writeTar.
+type tarFile struct {
+	name string
+	data string
+	typ  byte
+}
+
+func writeTar(t *testing.T, files ...tarFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range files {
+		typ := f.typ
+		if typ == 0 {
+			typ = tar.TypeReg
+		}
+		hdr := &tar.Header{Typeflag: typ, Name: f.name, Size: int64(len(f.data)), Mode: 0o644}
+		if typ == tar.TypeSymlink {
+			hdr.Size = 0
+			hdr.Linkname = f.data
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if typ == tar.TypeReg {
+			if _, err := tw.Write([]byte(f.data)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImportInvalid(t *testing.T) {
+	ctx := context.Background()
+	const (
+		layout = `{"imageLayoutVersion":"1.0.0"}`
+		blob   = `{"n":1}`
+	)
+	d := digest.FromString(blob)
+	index := `{"schemaVersion":2,"manifests":[{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"` + d.String() + `","size":7,"annotations":{"org.opencontainers.image.ref.name":"latest"}}]}`
+	blobPath := "blobs/sha256/" + d.Encoded()
+
+	for _, tt := range []struct {
+		name  string
+		files []tarFile
+	}{
+		{name: "missing oci-layout", files: []tarFile{{name: "index.json", data: index}, {name: blobPath, data: blob}}},
+		{name: "unsupported version", files: []tarFile{{name: "oci-layout", data: `{"imageLayoutVersion":"2.0.0"}`}, {name: "index.json", data: index}, {name: blobPath, data: blob}}},
+		{name: "missing index", files: []tarFile{{name: "oci-layout", data: layout}, {name: blobPath, data: blob}}},
+		{name: "missing blob", files: []tarFile{{name: "oci-layout", data: layout}, {name: "index.json", data: index}}},
+		{name: "corrupt blob", files: []tarFile{{name: "oci-layout", data: layout}, {name: "index.json", data: index}, {name: blobPath, data: `{"n":2}`}}},
+		{name: "misplaced blob", files: []tarFile{{name: "oci-layout", data: layout}, {name: "index.json", data: index}, {name: "blobs/sha512/" + d.Encoded(), data: blob}}},
+		{name: "traversal", files: []tarFile{{name: "oci-layout", data: layout}, {name: "index.json", data: index}, {name: "../" + blobPath, data: blob}}},
+		{name: "unexpected blob file", files: []tarFile{{name: "oci-layout", data: layout}, {name: "index.json", data: index}, {name: "blobs/sha256/README", data: "hello"}}},
+		{name: "invalid reference", files: []tarFile{{name: "oci-layout", data: layout}, {name: "index.json", data: strings.Replace(index, `"latest"`, `"latest/../x y"`, 1)}, {name: blobPath, data: blob}}},
+		{name: "symlink", files: []tarFile{{name: "oci-layout", data: layout}, {name: "index.json", data: index}, {name: blobPath, data: "/etc/passwd", typ: tar.TypeSymlink}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dst, err := Create(t.TempDir(), Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Import(ctx, dst, bytes.NewReader(writeTar(t, tt.files...))); err == nil {
+				t.Error("expected error")
+			}
+			if _, err := dst.Resolve(ctx, "latest"); err == nil {
+				t.Error("invalid archive was imported")
+			}
+			if exists, err := dst.Exists(ctx, v1.Descriptor{Digest: d, Size: 7}); err != nil || exists {
+				t.Errorf("blob of an invalid archive exists: %v, %v", exists, err)
+			}
+			checkNoTempFiles(t, dst.Dir())
+		})
+	}
+
+	// the blobs in the layout before a failed import are kept
+	dst, err := Create(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := pushBlob(t, dst, v1.MediaTypeImageManifest, []byte(blob))
+	archive := writeTar(t, tarFile{name: "oci-layout", data: layout}, tarFile{name: blobPath, data: blob})
+	if _, err := Import(ctx, dst, bytes.NewReader(archive)); err == nil {
+		t.Error("expected error")
+	}
+	if exists, err := dst.Exists(ctx, desc); err != nil || !exists {
+		t.Errorf("blob removed by a failed import: %v, %v", exists, err)
+	}
+
+	// the same entries in any order are valid, and unknown files are ignored
+	dst, err = Create(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive = writeTar(t, tarFile{name: "./" + blobPath, data: blob}, tarFile{name: "index.json", data: index}, tarFile{name: "manifest.json", data: "[]"},
+		tarFile{name: "./oci-layout", data: layout})
+	if _, err := Import(ctx, dst, bytes.NewReader(archive)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := content.ReadAll(ctx, dst, v1.Descriptor{Digest: d, Size: 7}, 1024); err != nil {
+		t.Error(err)
+	}
+}
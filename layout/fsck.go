@@ -0,0 +1,536 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/graph"
+	"github.com/opencontainers/image-spec/identity"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// IssueKind is the kind of an Issue found by Fsck.
+type IssueKind string
+
+// Kinds of issues.
+const (
+	// IssueUnsupportedVersion is a missing or invalid oci-layout, or one
+	// with another version than ImageLayoutVersion.
+	IssueUnsupportedVersion IssueKind = "unsupported-version"
+
+	// IssueInvalidIndex is a missing or invalid index.json.
+	IssueInvalidIndex IssueKind = "invalid-index"
+
+	// IssueCorruptBlob is a blob whose content does not match its digest.
+	IssueCorruptBlob IssueKind = "corrupt-blob"
+
+	// IssueTruncatedBlob is a corrupt blob smaller than a descriptor
+	// referencing it.
+	IssueTruncatedBlob IssueKind = "truncated-blob"
+
+	// IssueSizeMismatch is a valid blob referenced by a descriptor with
+	// another size.
+	IssueSizeMismatch IssueKind = "size-mismatch"
+
+	// IssueMisplacedBlob is a blob in the directory of another algorithm
+	// than the one of its digest.
+	IssueMisplacedBlob IssueKind = "misplaced-blob"
+
+	// IssueUnsupportedAlgorithm is a blob whose digest algorithm is not
+	// available, and is rejected by the algorithm policy.
+	IssueUnsupportedAlgorithm IssueKind = "unsupported-algorithm"
+
+	// IssueTempFile is a temporary file left by an interrupted Writer.
+	IssueTempFile IssueKind = "temp-file"
+
+	// IssueUnknownFile is a file of the blobs directory that is not a blob.
+	IssueUnknownFile IssueKind = "unknown-file"
+
+	// IssueMissingBlob is a blob referenced from index.json, directly or
+	// not, that is not in the layout.
+	IssueMissingBlob IssueKind = "missing-blob"
+
+	// IssueInvalidContent is a manifest or index that cannot be parsed.
+	IssueInvalidContent IssueKind = "invalid-content"
+
+	// IssueDuplicateTag is an AnnotationRefName of several entries of
+	// index.json.
+	IssueDuplicateTag IssueKind = "duplicate-tag"
+)
+
+// Issue is a problem found by Fsck.
+type Issue struct {
+	Kind IssueKind
+
+	// Path is the slash-separated path of the file with the issue, relative
+	// to the layout root, if any.
+	Path string
+
+	// Digest is the digest of the blob with the issue, if any.
+	Digest digest.Digest
+
+	// Message describes the issue.
+	Message string
+
+	// Suggestion describes how the issue can be repaired.
+	Suggestion string
+
+	// Repaired is set if the issue was repaired by Fsck.
+	Repaired bool
+}
+
+func (i Issue) String() string {
+	s := fmt.Sprintf("%s: %s", i.Kind, i.Message)
+	if i.Repaired {
+		s += " (repaired)"
+	}
+	return s
+}
+
+// FsckOptions are the options of Fsck.
+type FsckOptions struct {
+	// AlgorithmPolicy decides whether blobs with an unknown digest
+	// algorithm are accepted, as in Options.
+	AlgorithmPolicy identity.AlgorithmPolicy
+
+	// Quarantine, if not empty, is the directory corrupt and truncated blobs
+	// are moved to, at <alg>/<encoded>. It must not be in the layout blobs
+	// directory.
+	Quarantine string
+
+	// RewriteIndex removes the entries of index.json whose blob, or a blob
+	// they reference directly or not, is missing, including when it was
+	// quarantined.
+	RewriteIndex bool
+}
+
+// FsckReport is the result of Fsck.
+type FsckReport struct {
+	Issues []Issue
+
+	// Removed are the entries removed from index.json by RewriteIndex.
+	Removed []v1.Descriptor
+}
+
+// OK returns whether no issues were found.
+func (r FsckReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// fsck is the state of Fsck.
+type fsck struct {
+	w      *Writer
+	opts   FsckOptions
+	report FsckReport
+
+	// blobs are the blob files, by digest
+	blobs map[digest.Digest]*fsckBlob
+
+	// entries are the entries of index.json, with the digests reached from
+	// each
+	entries []fsckEntry
+}
+
+type fsckEntry struct {
+	desc    v1.Descriptor
+	reached map[digest.Digest]bool
+}
+
+type fsckBlob struct {
+	blobFile
+	valid      bool  // the content matches the digest
+	referenced int64 // the size of a descriptor referencing the blob, or -1
+}
+
+// Fsck checks the integrity of the layout in the directory dir, beyond
+// validation: the content of every blob is verified, every blob reachable
+// from index.json must be present, and the blobs directory must hold only
+// blobs. Unlike Open, it reports the problems with oci-layout instead of
+// failing.
+//
+// Issues are repaired according to opts: corrupt blobs are quarantined and
+// index.json is rewritten without dangling entries. Other issues are only
+// reported with a suggestion.
+func Fsck(ctx context.Context, dir string, opts FsckOptions) (FsckReport, error) {
+	c := &fsck{
+		w:     &Writer{Reader: &Reader{fsys: os.DirFS(dir), opts: Options{AlgorithmPolicy: opts.AlgorithmPolicy}}, dir: dir},
+		opts:  opts,
+		blobs: map[digest.Digest]*fsckBlob{},
+	}
+	c.checkVersion()
+	if err := c.scanRoot(); err != nil {
+		return FsckReport{}, err
+	}
+	if err := c.scanBlobs(); err != nil {
+		return FsckReport{}, err
+	}
+	index, indexErr := c.w.Index()
+	if indexErr != nil {
+		c.add(Issue{Kind: IssueInvalidIndex, Path: v1.ImageIndexFile, Message: indexErr.Error(),
+			Suggestion: "restore index.json, or recreate it from the manifests of the layout"})
+	} else {
+		c.checkTags(index)
+		if err := c.checkGraph(ctx, index); err != nil {
+			return FsckReport{}, err
+		}
+	}
+	c.checkBlobs()
+
+	if opts.Quarantine != "" {
+		if err := c.quarantine(); err != nil {
+			return c.report, err
+		}
+	}
+	if opts.RewriteIndex && indexErr == nil {
+		if err := c.rewriteIndex(); err != nil {
+			return c.report, err
+		}
+	}
+	return c.report, nil
+}
+
+func (c *fsck) add(issue Issue) {
+	c.report.Issues = append(c.report.Issues, issue)
+}
+
+func (c *fsck) checkVersion() {
+	raw, err := readFile(c.w.fsys, v1.ImageLayoutFile)
+	if err == nil {
+		var layout v1.ImageLayout
+		if err = json.Unmarshal(raw, &layout); err == nil && layout.Version != v1.ImageLayoutVersion {
+			err = fmt.Errorf("%w: %q", ErrUnsupportedVersion, layout.Version)
+		}
+	}
+	if err != nil {
+		c.add(Issue{Kind: IssueUnsupportedVersion, Path: v1.ImageLayoutFile, Message: err.Error(),
+			Suggestion: fmt.Sprintf(`write {"imageLayoutVersion":%q} to oci-layout if the layout follows this version`, v1.ImageLayoutVersion)})
+	}
+}
+
+// scanRoot reports the temporary files of the layout root.
+func (c *fsck) scanRoot() error {
+	entries, err := fs.ReadDir(c.w.fsys, ".")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), tempPrefix) {
+			c.add(tempFileIssue(entry.Name()))
+		}
+	}
+	return nil
+}
+
+func tempFileIssue(name string) Issue {
+	return Issue{Kind: IssueTempFile, Path: name, Message: fmt.Sprintf("temporary file %s", name),
+		Suggestion: "remove it if no writer is using the layout"}
+}
+
+// scanBlobs verifies the files of the blobs directory.
+func (c *fsck) scanBlobs() error {
+	algs, err := fs.ReadDir(c.w.fsys, v1.ImageBlobsDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, alg := range algs {
+		dir := path.Join(v1.ImageBlobsDir, alg.Name())
+		if !alg.IsDir() {
+			c.add(Issue{Kind: IssueUnknownFile, Path: dir, Message: fmt.Sprintf("%s is not an algorithm directory", dir),
+				Suggestion: "move it out of the layout"})
+			continue
+		}
+		entries, err := fs.ReadDir(c.w.fsys, dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := c.scanBlob(digest.Algorithm(alg.Name()), path.Join(dir, entry.Name()), entry); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *fsck) scanBlob(alg digest.Algorithm, name string, entry fs.DirEntry) error {
+	encoded := entry.Name()
+	switch {
+	case strings.HasPrefix(encoded, tempPrefix):
+		c.add(tempFileIssue(name))
+		return nil
+	case !entry.Type().IsRegular():
+		c.add(Issue{Kind: IssueUnknownFile, Path: name, Message: fmt.Sprintf("%s is not a regular file", name),
+			Suggestion: "move it out of the layout"})
+		return nil
+	}
+	fi, err := entry.Info()
+	if err != nil {
+		return err
+	}
+
+	d := digest.NewDigestFromEncoded(alg, encoded)
+	if p, err := BlobPath(d); err != nil || p != name {
+		if misplaced, err := c.checkMisplaced(name, alg, encoded); misplaced || err != nil {
+			return err
+		}
+		c.add(Issue{Kind: IssueUnknownFile, Path: name, Message: fmt.Sprintf("%s is not the path of a digest", name),
+			Suggestion: "move it out of the layout"})
+		return nil
+	}
+
+	blob := &fsckBlob{blobFile: blobFile{Blob: Blob{Digest: d, Size: fi.Size()}, path: name}, referenced: -1}
+	c.blobs[d] = blob
+	verifiable, err := identity.CheckAlgorithm(d, c.opts.AlgorithmPolicy)
+	if err != nil {
+		c.add(Issue{Kind: IssueUnsupportedAlgorithm, Path: name, Digest: d, Message: err.Error(),
+			Suggestion: "accept unknown algorithms, or remove the blob"})
+		blob.valid = true // not verifiable, and reported
+		return nil
+	}
+	if !verifiable {
+		blob.valid = true
+		return nil
+	}
+	actual, err := c.digestFile(name, alg)
+	if err != nil {
+		return err
+	}
+	blob.valid = actual == d
+	if !blob.valid {
+		// the encoded digest may be the one of another algorithm of the
+		// same length, such as sha256 and blake3
+		misplaced, err := c.checkMisplaced(name, alg, encoded)
+		if misplaced {
+			delete(c.blobs, d)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkMisplaced reports the blob name, in the directory of alg, if its
+// content matches the encoded digest with another algorithm, and returns
+// whether it does.
+func (c *fsck) checkMisplaced(name string, alg digest.Algorithm, encoded string) (bool, error) {
+	for _, other := range []digest.Algorithm{digest.SHA256, digest.SHA384, digest.SHA512, digest.BLAKE3} {
+		d := digest.NewDigestFromEncoded(other, encoded)
+		if other == alg || d.Validate() != nil {
+			continue
+		}
+		actual, err := c.digestFile(name, other)
+		if err != nil {
+			return false, err
+		}
+		if actual == d {
+			p, _ := BlobPath(d)
+			c.add(Issue{Kind: IssueMisplacedBlob, Path: name, Digest: d, Message: fmt.Sprintf("blob %s is stored at %s", d, name),
+				Suggestion: fmt.Sprintf("move it to %s", p)})
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// digestFile returns the digest of the file name with alg.
+func (c *fsck) digestFile(name string, alg digest.Algorithm) (digest.Digest, error) {
+	f, err := c.w.fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return alg.FromReader(f)
+}
+
+// checkTags reports the AnnotationRefName of several entries of index.
+func (c *fsck) checkTags(index v1.Index) {
+	counts := map[string]int{}
+	var names []string
+	for _, desc := range index.Manifests {
+		name, ok := desc.Annotations[v1.AnnotationRefName]
+		if !ok {
+			continue
+		}
+		if counts[name] == 0 {
+			names = append(names, name)
+		}
+		counts[name]++
+	}
+	for _, name := range names {
+		if counts[name] > 1 {
+			c.add(Issue{Kind: IssueDuplicateTag, Path: v1.ImageIndexFile,
+				Message:    fmt.Sprintf("reference %q is the name of %d entries", name, counts[name]),
+				Suggestion: "remove or rename all but one of the entries"})
+		}
+	}
+}
+
+// checkGraph walks the graph from each entry of index, reporting missing
+// blobs and invalid manifests, and recording the size blobs are referenced
+// with and the digests reached from the entry.
+func (c *fsck) checkGraph(ctx context.Context, index v1.Index) error {
+	missing := map[digest.Digest]bool{}
+	var reached map[digest.Digest]bool
+	opts := graph.Options{
+		PreOrder: func(_ context.Context, node graph.Node) error {
+			desc := node.Descriptor
+			reached[desc.Digest] = true
+			blob, ok := c.blobs[desc.Digest]
+			if !ok {
+				// blobs without children are visited for each reference
+				if missing[desc.Digest] {
+					return graph.SkipChildren
+				}
+				missing[desc.Digest] = true
+				from := v1.ImageIndexFile
+				if len(node.Parents) > 0 {
+					from = node.Parents[len(node.Parents)-1].Digest.String()
+				}
+				c.add(Issue{Kind: IssueMissingBlob, Digest: desc.Digest, Message: fmt.Sprintf("blob %s referenced by %s is missing", desc.Digest, from),
+					Suggestion: "push the blob again, or remove the index.json entries depending on it"})
+				return graph.SkipChildren
+			}
+			if blob.referenced < 0 {
+				blob.referenced = desc.Size
+			}
+			if !blob.valid || blob.Size != desc.Size {
+				// reported by checkBlobs
+				return graph.SkipChildren
+			}
+			return nil
+		},
+	}
+	for _, desc := range index.Manifests {
+		// the graph of each entry is walked whole, to know its blobs
+		reached = map[digest.Digest]bool{}
+		c.entries = append(c.entries, fsckEntry{desc: desc, reached: reached})
+		opts.Visited = graph.NewSet()
+		err := graph.Walk(ctx, c.w, desc, opts)
+		if err != nil && ctx.Err() != nil {
+			return err
+		}
+		if err != nil {
+			c.add(Issue{Kind: IssueInvalidContent, Digest: desc.Digest, Message: err.Error(),
+				Suggestion: "push valid content, or remove the index.json entry"})
+		}
+	}
+	return nil
+}
+
+// checkBlobs reports the corrupt blobs, and the valid blobs referenced with
+// another size.
+func (c *fsck) checkBlobs() {
+	var blobs []*fsckBlob
+	for _, blob := range c.blobs {
+		blobs = append(blobs, blob)
+	}
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].Digest < blobs[j].Digest })
+	for _, blob := range blobs {
+		switch {
+		case !blob.valid && blob.referenced > blob.Size:
+			c.add(Issue{Kind: IssueTruncatedBlob, Path: blob.path, Digest: blob.Digest,
+				Message:    fmt.Sprintf("blob %s has %d of %d bytes", blob.Digest, blob.Size, blob.referenced),
+				Suggestion: "quarantine the blob and push it again"})
+		case !blob.valid:
+			c.add(Issue{Kind: IssueCorruptBlob, Path: blob.path, Digest: blob.Digest,
+				Message:    fmt.Sprintf("blob %s does not match its digest", blob.Digest),
+				Suggestion: "quarantine the blob and push it again"})
+		case blob.referenced >= 0 && blob.referenced != blob.Size:
+			c.add(Issue{Kind: IssueSizeMismatch, Path: blob.path, Digest: blob.Digest,
+				Message:    fmt.Sprintf("blob %s of %d bytes is referenced with size %d", blob.Digest, blob.Size, blob.referenced),
+				Suggestion: "fix the descriptors referencing the blob"})
+		}
+	}
+}
+
+// quarantine moves the corrupt and truncated blobs to the quarantine
+// directory.
+func (c *fsck) quarantine() (err error) {
+	unlock, err := c.w.Lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if uerr := unlock(); err == nil {
+			err = uerr
+		}
+	}()
+	for i, issue := range c.report.Issues {
+		if issue.Kind != IssueCorruptBlob && issue.Kind != IssueTruncatedBlob {
+			continue
+		}
+		dst := filepath.Join(c.opts.Quarantine, issue.Digest.Algorithm().String(), issue.Digest.Encoded())
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := os.Rename(filepath.Join(c.w.dir, filepath.FromSlash(issue.Path)), dst); err != nil {
+			return err
+		}
+		c.report.Issues[i].Repaired = true
+		delete(c.blobs, issue.Digest)
+	}
+	return nil
+}
+
+// rewriteIndex removes the entries of index.json reaching a missing blob.
+// It fails if index.json is no longer the one checked.
+func (c *fsck) rewriteIndex() error {
+	return c.w.UpdateIndex(func(index *v1.Index) error {
+		if len(index.Manifests) != len(c.entries) {
+			return errIndexChanged
+		}
+		for i, desc := range index.Manifests {
+			checked := c.entries[i].desc
+			if desc.Digest != checked.Digest || desc.Annotations[v1.AnnotationRefName] != checked.Annotations[v1.AnnotationRefName] {
+				return errIndexChanged
+			}
+		}
+		var kept []v1.Descriptor
+		for i, desc := range index.Manifests {
+			if c.complete(c.entries[i].reached) {
+				kept = append(kept, desc)
+			} else {
+				c.report.Removed = append(c.report.Removed, desc)
+			}
+		}
+		index.Manifests = kept
+		return nil
+	})
+}
+
+var errIndexChanged = errors.New("index.json changed during fsck")
+
+// complete returns whether the blobs of reached are all in the layout.
+func (c *fsck) complete(reached map[digest.Digest]bool) bool {
+	for d := range reached {
+		if _, ok := c.blobs[d]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/builder"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func issueKinds(report FsckReport) []string {
+	var kinds []string
+	for _, issue := range report.Issues {
+		kinds = append(kinds, string(issue.Kind))
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+func checkIssueKinds(t *testing.T, report FsckReport, want ...IssueKind) {
+	t.Helper()
+	var wantKinds []string
+	for _, kind := range want {
+		wantKinds = append(wantKinds, string(kind))
+	}
+	sort.Strings(wantKinds)
+	got := issueKinds(report)
+	if len(got) != len(wantKinds) {
+		t.Fatalf("issues %v, want kinds %v", report.Issues, wantKinds)
+	}
+	for i := range got {
+		if got[i] != wantKinds[i] {
+			t.Fatalf("issues %v, want kinds %v", report.Issues, wantKinds)
+		}
+	}
+}
+
+func TestFsck(t *testing.T) {
+	ctx := context.Background()
+	w, err := Create(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := pushBlob(t, w, v1.MediaTypeImageConfig, []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`))
+	layer := pushBlob(t, w, v1.MediaTypeImageLayer, []byte("layer"))
+	truncated := pushBlob(t, w, v1.MediaTypeImageLayer, []byte("truncated layer"))
+	corrupt := pushBlob(t, w, v1.MediaTypeImageLayer, []byte("corrupt blob"))
+	image := pushManifest(t, w, builder.NewManifest().Validate(builder.NoValidation).ConfigDescriptor(config).LayerDescriptor(layer))
+	broken := pushManifest(t, w, builder.NewManifest().Validate(builder.NoValidation).ConfigDescriptor(config).LayerDescriptor(truncated))
+	if err := w.Tag(ctx, image, "latest"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Tag(ctx, broken, "broken"); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Fsck(ctx, w.Dir(), FsckOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() {
+		t.Fatalf("unexpected issues in a valid layout: %v", report.Issues)
+	}
+
+	// damage the layout
+	missing := v1.Descriptor{MediaType: v1.MediaTypeImageManifest, Digest: digest.FromString("missing"), Size: 7}
+	if err := w.UpdateIndex(func(index *v1.Index) error {
+		duplicate := broken
+		duplicate.Annotations = map[string]string{v1.AnnotationRefName: "latest"}
+		index.Manifests = append(index.Manifests, missing, duplicate)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	blobPath := func(d digest.Digest) string {
+		p, err := BlobPath(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return filepath.Join(w.Dir(), filepath.FromSlash(p))
+	}
+	misplaced := []byte("misplaced")
+	sum := sha256.Sum256(misplaced)
+	// sha256 and blake3 digests have the same length
+	misplacedBLAKE3 := []byte("misplaced in blake3")
+	sumBLAKE3 := sha256.Sum256(misplacedBLAKE3)
+	misplacedBLAKE3Path := filepath.Join(w.Dir(), v1.ImageBlobsDir, "blake3", hex.EncodeToString(sumBLAKE3[:]))
+	files := map[string][]byte{
+		blobPath(truncated.Digest):                 []byte("truncated"),
+		blobPath(corrupt.Digest):                   []byte("corrupt blub"),
+		filepath.Join(w.Dir(), v1.ImageLayoutFile): []byte(`{"imageLayoutVersion":"2.0.0"}`),
+		filepath.Join(w.Dir(), v1.ImageBlobsDir, "sha512", hex.EncodeToString(sum[:])): misplaced,
+		misplacedBLAKE3Path: misplacedBLAKE3,
+		filepath.Join(w.Dir(), v1.ImageBlobsDir, "sha256", tempPrefix+"upload"): []byte("partial"),
+		filepath.Join(w.Dir(), v1.ImageBlobsDir, "sha256", "README"):            []byte("readme"),
+	}
+	for name, data := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err = Fsck(ctx, w.Dir(), FsckOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkIssueKinds(t, report, IssueUnsupportedVersion, IssueMisplacedBlob, IssueMisplacedBlob, IssueTempFile, IssueUnknownFile,
+		IssueDuplicateTag, IssueMissingBlob, IssueTruncatedBlob, IssueCorruptBlob)
+	for _, issue := range report.Issues {
+		if issue.Suggestion == "" || issue.Repaired {
+			t.Errorf("issue %v: suggestion %q, repaired %v", issue, issue.Suggestion, issue.Repaired)
+		}
+	}
+
+	quarantine := t.TempDir()
+	report, err = Fsck(ctx, w.Dir(), FsckOptions{Quarantine: quarantine, RewriteIndex: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range report.Issues {
+		want := issue.Kind == IssueTruncatedBlob || issue.Kind == IssueCorruptBlob
+		if issue.Repaired != want {
+			t.Errorf("issue %v: repaired %v, want %v", issue, issue.Repaired, want)
+		}
+	}
+	for _, d := range []digest.Digest{truncated.Digest, corrupt.Digest} {
+		if _, err := os.Stat(filepath.Join(quarantine, d.Algorithm().String(), d.Encoded())); err != nil {
+			t.Errorf("blob %s not quarantined: %v", d, err)
+		}
+	}
+	if _, err := os.Stat(misplacedBLAKE3Path); err != nil {
+		t.Errorf("misplaced blob quarantined: %v", err)
+	}
+	// the entries of the manifest whose layer was quarantined are removed
+	want := []digest.Digest{broken.Digest, missing.Digest, broken.Digest}
+	if len(report.Removed) != len(want) {
+		t.Fatalf("removed entries %v, want %v", report.Removed, want)
+	}
+	for i, desc := range report.Removed {
+		if desc.Digest != want[i] {
+			t.Errorf("removed entries %v, want %v", report.Removed, want)
+		}
+	}
+	index, err := w.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Manifests) != 1 || index.Manifests[0].Digest != image.Digest {
+		t.Errorf("index entries %v, want %s", index.Manifests, image.Digest)
+	}
+
+	report, err = Fsck(ctx, w.Dir(), FsckOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkIssueKinds(t, report, IssueUnsupportedVersion, IssueMisplacedBlob, IssueMisplacedBlob, IssueTempFile, IssueUnknownFile)
+}
+
+func TestFsckIndexChanged(t *testing.T) {
+	ctx := context.Background()
+	w, err := Create(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := pushBlob(t, w, v1.MediaTypeImageConfig, []byte("{}"))
+	image := pushManifest(t, w, builder.NewManifest().Validate(builder.NoValidation).ConfigDescriptor(config))
+	if err := w.Tag(ctx, image, "latest"); err != nil {
+		t.Fatal(err)
+	}
+	report, err := Fsck(ctx, w.Dir(), FsckOptions{RewriteIndex: true})
+	if err != nil || !report.OK() {
+		t.Fatalf("unexpected result for a valid layout: %v, %v", report.Issues, err)
+	}
+
+	// an entry replaced after the check, with the same number of entries
+	c := &fsck{w: w, blobs: map[digest.Digest]*fsckBlob{}, entries: []fsckEntry{{
+		desc:    v1.Descriptor{Digest: digest.FromString("replaced"), Annotations: map[string]string{v1.AnnotationRefName: "latest"}},
+		reached: map[digest.Digest]bool{digest.FromString("replaced"): true},
+	}}}
+	if err := c.rewriteIndex(); err == nil {
+		t.Error("expected error")
+	}
+	if desc, err := w.Resolve(ctx, "latest"); err != nil || desc.Digest != image.Digest {
+		t.Errorf("index rewritten: %v, %v", desc, err)
+	}
+}
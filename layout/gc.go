@@ -0,0 +1,228 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/graph"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// DefaultGracePeriod is the grace period of GC when GCOptions.GracePeriod is
+// zero.
+const DefaultGracePeriod = 24 * time.Hour
+
+// maxManifestSize is the maximum size of the blobs read to find the
+// manifests and indexes with a subject.
+const maxManifestSize = 4 << 20
+
+// GCOptions are the options of Writer.GC.
+type GCOptions struct {
+	// Referrers keeps the manifests whose subject is kept, and what they
+	// reference, as a registry would list them as referrers of the subject.
+	Referrers bool
+
+	// DryRun lists the blobs that would be removed without removing them.
+	DryRun bool
+
+	// GracePeriod keeps the blobs modified less than this duration before
+	// the collection, which may have been pushed by a concurrent writer that
+	// has not yet added them to index.json. Writers refresh the modification
+	// time of the blobs they push or find to exist, so this also protects
+	// existing blobs a writer is about to reference.
+	//
+	// DefaultGracePeriod is used if it is zero. A negative duration also
+	// removes recent blobs, which is only safe if no writer uses the layout.
+	GracePeriod time.Duration
+}
+
+// Blob identifies a blob of a layout.
+type Blob struct {
+	Digest digest.Digest
+	Size   int64
+}
+
+// GCResult is the result of Writer.GC.
+type GCResult struct {
+	// Removed lists the blobs removed, or that would be with DryRun,
+	// sorted by digest.
+	Removed []Blob
+
+	// Size is the total size of Removed.
+	Size int64
+}
+
+// GC removes the blobs that are not reachable from index.json, through
+// indexes and manifests. The lock of the layout is held during the
+// collection, such that index.json is not updated concurrently.
+//
+// Files of the blobs directory that are not blobs, such as the temporary
+// files of a Writer, are left alone.
+func (w *Writer) GC(ctx context.Context, opts GCOptions) (result GCResult, err error) {
+	start := time.Now()
+	grace := opts.GracePeriod
+	if grace == 0 {
+		grace = DefaultGracePeriod
+	}
+	unlock, err := w.Lock()
+	if err != nil {
+		return GCResult{}, err
+	}
+	defer func() {
+		if uerr := unlock(); err == nil {
+			err = uerr
+		}
+	}()
+
+	blobs, err := w.blobs()
+	if err != nil {
+		return GCResult{}, err
+	}
+	index, err := w.Index()
+	if err != nil {
+		return GCResult{}, err
+	}
+	marked, visited := graph.NewSet(), graph.NewSet()
+	if err := w.mark(ctx, marked, visited, index.Manifests...); err != nil {
+		return GCResult{}, err
+	}
+	if opts.Referrers {
+		if err := w.markReferrers(ctx, marked, visited); err != nil {
+			return GCResult{}, err
+		}
+	}
+
+	for _, blob := range blobs {
+		if marked.Contains(blob.Digest) || blob.modTime.After(start.Add(-grace)) {
+			continue
+		}
+		if !opts.DryRun {
+			if err := os.Remove(filepath.Join(w.dir, filepath.FromSlash(blob.path))); err != nil {
+				return result, err
+			}
+		}
+		result.Removed = append(result.Removed, Blob{Digest: blob.Digest, Size: blob.Size})
+		result.Size += blob.Size
+	}
+	return result, nil
+}
+
+// blobFile is a blob file of the layout.
+type blobFile struct {
+	Blob
+	path    string // slash-separated, relative to the layout root
+	modTime time.Time
+}
+
+// blobs lists the blob files of the layout, sorted by digest. Files whose
+// path is not the path of a digest are ignored.
+func (r *Reader) blobs() ([]blobFile, error) {
+	var blobs []blobFile
+	algs, err := fs.ReadDir(r.fsys, v1.ImageBlobsDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, alg := range algs {
+		if !alg.IsDir() {
+			continue
+		}
+		entries, err := fs.ReadDir(r.fsys, path.Join(v1.ImageBlobsDir, alg.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			d := digest.NewDigestFromEncoded(digest.Algorithm(alg.Name()), entry.Name())
+			name, err := BlobPath(d)
+			if err != nil || !entry.Type().IsRegular() || path.Base(name) != entry.Name() {
+				continue
+			}
+			fi, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			blobs = append(blobs, blobFile{
+				Blob:    Blob{Digest: d, Size: fi.Size()},
+				path:    name,
+				modTime: fi.ModTime(),
+			})
+		}
+	}
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].Digest < blobs[j].Digest })
+	return blobs, nil
+}
+
+// mark marks the blobs reachable from descs, not walking again the manifests
+// and indexes in visited.
+func (w *Writer) mark(ctx context.Context, marked, visited *graph.Set, descs ...v1.Descriptor) error {
+	opts := graph.Options{
+		Visited: visited,
+		PreOrder: func(ctx context.Context, node graph.Node) error {
+			marked.Add(node.Descriptor.Digest)
+			// nothing is reachable through a missing manifest; Reader.Exists
+			// does not refresh the blobs, unlike Writer.Exists
+			exists, err := w.Reader.Exists(ctx, node.Descriptor)
+			if err != nil || exists {
+				return err
+			}
+			return graph.SkipChildren
+		},
+	}
+	for _, desc := range descs {
+		if err := graph.Walk(ctx, w, desc, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markReferrers marks the manifests of the layout whose subject is marked,
+// and the blobs reachable from them, until there are no more.
+func (w *Writer) markReferrers(ctx context.Context, marked, visited *graph.Set) error {
+	referrers, err := w.referrerBlobs(ctx)
+	if err != nil {
+		return err
+	}
+	done := map[digest.Digest]bool{}
+	for found := true; found; {
+		found = false
+		for _, r := range referrers {
+			if done[r.desc.Digest] || !marked.Contains(r.subject) {
+				continue
+			}
+			if err := w.mark(ctx, marked, visited, r.desc); err != nil {
+				return err
+			}
+			done[r.desc.Digest] = true
+			found = true
+		}
+	}
+	return nil
+}
+
+func isIndex(mediaType string) bool {
+	return mediaType == v1.MediaTypeImageIndex || mediaType == "application/vnd.docker.distribution.manifest.list.v2+json"
+}
+
+func isManifest(mediaType string) bool {
+	return mediaType == v1.MediaTypeImageManifest || mediaType == "application/vnd.docker.distribution.manifest.v2+json"
+}
@@ -0,0 +1,276 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/builder"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// pushManifest pushes the manifest built by m.
+func pushManifest(t *testing.T, w *Writer, m *builder.Manifest) v1.Descriptor {
+	t.Helper()
+	result, err := m.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Push(context.Background(), result.Descriptor, bytes.NewReader(result.Bytes)); err != nil {
+		t.Fatal(err)
+	}
+	return result.Descriptor
+}
+
+// gcLayout is a layout with a tagged image and its referrers, along with
+// unreachable blobs.
+type gcLayout struct {
+	w                                   *Writer
+	tagged, shared, untagged, artifacts []digest.Digest
+	referrer, nested, orphanReferrer    digest.Digest
+	temp                                string
+}
+
+func newGCLayout(t *testing.T) gcLayout {
+	t.Helper()
+	ctx := context.Background()
+	w, err := Create(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := pushBlob(t, w, v1.MediaTypeImageConfig, []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`))
+	shared := pushBlob(t, w, v1.MediaTypeImageLayer, []byte("shared layer"))
+	own := pushBlob(t, w, v1.MediaTypeImageLayer, []byte("tagged layer"))
+	other := pushBlob(t, w, v1.MediaTypeImageLayer, []byte("untagged layer"))
+
+	image := pushManifest(t, w, builder.NewManifest().Validate(builder.NoValidation).ConfigDescriptor(config).LayerDescriptor(shared).LayerDescriptor(own))
+	untagged := pushManifest(t, w, builder.NewManifest().Validate(builder.NoValidation).ConfigDescriptor(config).LayerDescriptor(shared).LayerDescriptor(other))
+	if err := w.Tag(ctx, image, "latest"); err != nil {
+		t.Fatal(err)
+	}
+
+	empty := pushBlob(t, w, v1.MediaTypeEmptyJSON, v1.DescriptorEmptyJSON.Data)
+	signature := pushBlob(t, w, "application/example.signature", []byte("signature"))
+	referrer := pushManifest(t, w, builder.NewManifest().Validate(builder.NoValidation).ArtifactType("application/example.signature").LayerDescriptor(signature).Subject(image))
+	nested := pushManifest(t, w, builder.NewManifest().Validate(builder.NoValidation).ArtifactType("application/example.attestation").Subject(referrer))
+	orphan := pushManifest(t, w, builder.NewManifest().Validate(builder.NoValidation).ArtifactType("application/example.signature").Subject(untagged))
+
+	temp := filepath.Join(w.Dir(), v1.ImageBlobsDir, "sha256", tempPrefix+"upload")
+	if err := os.WriteFile(temp, []byte("partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return gcLayout{
+		w:              w,
+		tagged:         []digest.Digest{image.Digest, own.Digest},
+		shared:         []digest.Digest{shared.Digest, config.Digest},
+		untagged:       []digest.Digest{untagged.Digest, other.Digest},
+		referrer:       referrer.Digest,
+		nested:         nested.Digest,
+		orphanReferrer: orphan.Digest,
+		artifacts:      []digest.Digest{empty.Digest, signature.Digest},
+		temp:           temp,
+	}
+}
+
+func removedDigests(result GCResult) []digest.Digest {
+	var removed []digest.Digest
+	for _, blob := range result.Removed {
+		removed = append(removed, blob.Digest)
+	}
+	return removed
+}
+
+func sortedDigests(digests ...digest.Digest) []digest.Digest {
+	sort.Slice(digests, func(i, j int) bool { return digests[i] < digests[j] })
+	return digests
+}
+
+func TestGC(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("referrers", func(t *testing.T) {
+		l := newGCLayout(t)
+		result, err := l.w.GC(ctx, GCOptions{Referrers: true, GracePeriod: -1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := sortedDigests(l.untagged[0], l.untagged[1], l.orphanReferrer)
+		if removed := removedDigests(result); !equalDigests(removed, expected) {
+			t.Errorf("removed %v, expected %v", removed, expected)
+		}
+		checkBlobs(t, l.w, true, append(append(append([]digest.Digest{l.referrer, l.nested}, l.tagged...), l.shared...), l.artifacts...)...)
+		checkBlobs(t, l.w, false, expected...)
+		if _, err := os.Stat(l.temp); err != nil {
+			t.Errorf("temporary file removed: %v", err)
+		}
+	})
+
+	t.Run("without referrers", func(t *testing.T) {
+		l := newGCLayout(t)
+		result, err := l.w.GC(ctx, GCOptions{GracePeriod: -1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := sortedDigests(l.untagged[0], l.untagged[1], l.orphanReferrer, l.referrer, l.nested, l.artifacts[0], l.artifacts[1])
+		if removed := removedDigests(result); !equalDigests(removed, expected) {
+			t.Errorf("removed %v, expected %v", removed, expected)
+		}
+		checkBlobs(t, l.w, true, append(l.tagged, l.shared...)...)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		l := newGCLayout(t)
+		result, err := l.w.GC(ctx, GCOptions{Referrers: true, DryRun: true, GracePeriod: -1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Removed) != 3 {
+			t.Errorf("unexpected blobs to remove: %v", result.Removed)
+		}
+		var size int64
+		for _, blob := range result.Removed {
+			size += blob.Size
+		}
+		if size == 0 || result.Size != size {
+			t.Errorf("unexpected size %d, expected %d", result.Size, size)
+		}
+		checkBlobs(t, l.w, true, l.untagged...)
+	})
+
+	t.Run("grace period", func(t *testing.T) {
+		l := newGCLayout(t)
+		for _, opts := range []GCOptions{{}, {GracePeriod: time.Hour}} {
+			result, err := l.w.GC(ctx, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Removed) != 0 {
+				t.Errorf("recent blobs removed with grace period %v: %v", opts.GracePeriod, result.Removed)
+			}
+		}
+	})
+
+	t.Run("manifest reached as a blob", func(t *testing.T) {
+		// the untagged manifest is referenced as a layer, and with an
+		// invalid size, before being referenced as a manifest
+		l := newGCLayout(t)
+		fi, err := os.Stat(blobFilePath(t, l.w, l.untagged[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest := v1.Descriptor{MediaType: v1.MediaTypeImageManifest, Digest: l.untagged[0], Size: fi.Size()}
+		asLayer, invalid := manifest, manifest
+		asLayer.MediaType = v1.MediaTypeImageLayer
+		invalid.Size++
+		err = l.w.UpdateIndex(func(index *v1.Index) error {
+			index.Manifests = append([]v1.Descriptor{asLayer, invalid}, append(index.Manifests, manifest)...)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := l.w.GC(ctx, GCOptions{GracePeriod: -1}); err != nil {
+			t.Fatal(err)
+		}
+		checkBlobs(t, l.w, true, append(append(l.tagged, l.shared...), l.untagged...)...)
+	})
+
+	t.Run("refreshed", func(t *testing.T) {
+		// the untagged manifest and its layer are old, but a writer is
+		// about to reference them
+		l := newGCLayout(t)
+		old := time.Now().Add(-2 * DefaultGracePeriod)
+		blobs, err := l.w.blobs()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, blob := range blobs {
+			if err := os.Chtimes(filepath.Join(l.w.Dir(), filepath.FromSlash(blob.path)), old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+		manifest, err := os.ReadFile(blobFilePath(t, l.w, l.untagged[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := l.w.Push(ctx, v1.Descriptor{Digest: l.untagged[0], Size: int64(len(manifest))}, bytes.NewReader(manifest)); err != nil {
+			t.Fatal(err)
+		}
+		fi, err := os.Stat(blobFilePath(t, l.w, l.untagged[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists, err := l.w.Exists(ctx, v1.Descriptor{Digest: l.untagged[1], Size: fi.Size()}); err != nil || !exists {
+			t.Fatalf("blob %s: exists %v, %v", l.untagged[1], exists, err)
+		}
+
+		result, err := l.w.GC(ctx, GCOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := sortedDigests(l.orphanReferrer, l.referrer, l.nested, l.artifacts[0], l.artifacts[1])
+		if removed := removedDigests(result); !equalDigests(removed, expected) {
+			t.Errorf("removed %v, expected %v", removed, expected)
+		}
+		checkBlobs(t, l.w, true, l.untagged...)
+	})
+}
+
+// blobFilePath returns the path of the blob d of w.
+func blobFilePath(t *testing.T, w *Writer, d digest.Digest) string {
+	t.Helper()
+	name, err := BlobPath(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(w.Dir(), filepath.FromSlash(name))
+}
+
+func equalDigests(a, b []digest.Digest) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkBlobs checks whether the blobs of digests exist in the layout of w.
+func checkBlobs(t *testing.T, w *Writer, exist bool, digests ...digest.Digest) {
+	t.Helper()
+	for _, d := range digests {
+		name, err := BlobPath(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = os.Stat(filepath.Join(w.Dir(), name))
+		if exist && err != nil {
+			t.Errorf("blob %s was removed", d)
+		}
+		if !exist && err == nil {
+			t.Errorf("blob %s was kept", d)
+		}
+	}
+}
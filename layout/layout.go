@@ -0,0 +1,41 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package layout reads and writes OCI image layouts, directories storing
+// blobs by digest along with an index of their entry points, as specified
+// in image-layout.md.
+package layout
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// BlobPath returns the slash-separated path of the blob with digest d,
+// relative to the root of a layout: blobs/<alg>/<encoded>.
+//
+// The path of a well-formed digest is returned even if its algorithm is not
+// available, such that the blob can still be copied or removed. Content must
+// be verified with identity.VerifyDescriptor, which applies a policy to such
+// digests.
+func BlobPath(d digest.Digest) (string, error) {
+	if _, err := identity.CheckAlgorithm(d, identity.AcceptUnknownAlgorithms); err != nil {
+		return "", fmt.Errorf("invalid blob digest %q: %w", d, err)
+	}
+	return path.Join(v1.ImageBlobsDir, d.Algorithm().String(), d.Encoded()), nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+func TestBlobPath(t *testing.T) {
+	for _, tt := range []struct {
+		digest   digest.Digest
+		expected string
+	}{
+		{digest: digest.FromString("foo"), expected: "blobs/sha256/" + digest.FromString("foo").Encoded()},
+		{digest: digest.BLAKE3.FromString("foo"), expected: "blobs/blake3/" + digest.BLAKE3.FromString("foo").Encoded()},
+		{digest: "unknown+alg:0123456789abcdef", expected: "blobs/unknown+alg/0123456789abcdef"},
+		{digest: ""},
+		{digest: "sha256:../../etc/passwd"},
+		{digest: "sha256:0123"},
+		{digest: "../x:abcdef"},
+	} {
+		p, err := BlobPath(tt.digest)
+		switch {
+		case tt.expected == "" && err == nil:
+			t.Errorf("%q: expected error, got %s", tt.digest, p)
+		case tt.expected != "" && err != nil:
+			t.Errorf("%q: %v", tt.digest, err)
+		case p != tt.expected:
+			t.Errorf("%q: unexpected path %s", tt.digest, p)
+		}
+	}
+}
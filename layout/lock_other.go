@@ -0,0 +1,43 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package layout
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// lockFile takes an exclusive lock by creating the file name, waiting while
+// it exists, and returns the function releasing it by removing the file.
+//
+// Unlike file locks, the lock is not released if the process exits without
+// releasing it, in which case name must be removed manually.
+func lockFile(name string) (func() error, error) {
+	for {
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			f.Close()
+			return func() error { return os.Remove(name) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,348 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/opencontainers/image-spec/identity"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ErrRefConflict is returned by FailOnConflict.
+var ErrRefConflict = errors.New("reference name conflict")
+
+// LinkMode is how Merge adds the blobs of the sources to the destination.
+type LinkMode int
+
+const (
+	// CopyBlobs copies the blobs.
+	CopyBlobs LinkMode = iota
+
+	// HardlinkBlobs hard links the blobs to those of the sources, which must
+	// then never be modified, and copies them if it fails, for instance
+	// across file systems. The modification time of the linked blobs is
+	// refreshed for the grace period of GC, which also changes it in the
+	// sources.
+	HardlinkBlobs
+
+	// ReflinkBlobs clones the blobs, sharing their storage with those of the
+	// sources until either is modified, on file systems supporting it, and
+	// copies them otherwise.
+	ReflinkBlobs
+)
+
+// ConflictPolicy decides the AnnotationRefName of the entries of a source
+// named name, when the destination has different entries with that name.
+// taken reports whether a name is used in the destination or the source.
+//
+// It returns name to replace the entries of the destination, another name to
+// rename the entries of the source, or an error to fail.
+type ConflictPolicy func(name string, taken func(string) bool) (string, error)
+
+// FailOnConflict is a ConflictPolicy failing with an error wrapping
+// ErrRefConflict.
+func FailOnConflict(name string, _ func(string) bool) (string, error) {
+	return "", fmt.Errorf("%w: %q", ErrRefConflict, name)
+}
+
+// OverwriteOnConflict is a ConflictPolicy replacing the entries of the
+// destination.
+func OverwriteOnConflict(name string, _ func(string) bool) (string, error) {
+	return name, nil
+}
+
+// RenameOnConflict is a ConflictPolicy renaming the entries of the source to
+// the first name of the form "<name>-<n>" that is not taken, n starting at 2.
+func RenameOnConflict(name string, taken func(string) bool) (string, error) {
+	for n := 2; ; n++ {
+		if renamed := fmt.Sprintf("%s-%d", name, n); !taken(renamed) {
+			return renamed, nil
+		}
+	}
+}
+
+// MergeOptions are the options of Merge.
+type MergeOptions struct {
+	Link LinkMode
+
+	// Conflict resolves the conflicts of AnnotationRefName. A nil policy
+	// fails.
+	Conflict ConflictPolicy
+}
+
+// RefRename is an AnnotationRefName of a source renamed by Merge.
+type RefRename struct {
+	Source   string
+	From, To string
+}
+
+// MergeResult is the summary of Merge.
+type MergeResult struct {
+	// Copied, Linked and Deduplicated are the blobs of the sources that were
+	// copied, linked, or already in the destination.
+	Copied, Linked, Deduplicated []Blob
+
+	// Renamed are the renamed AnnotationRefName.
+	Renamed []RefRename
+
+	// Replaced are the AnnotationRefName whose entries of the destination
+	// were replaced.
+	Replaced []string
+}
+
+// Written returns the number of bytes written to the destination.
+func (r MergeResult) Written() int64 {
+	return blobsSize(r.Copied)
+}
+
+// Saved returns the number of bytes that were not written to the
+// destination, as the blobs were linked or deduplicated.
+func (r MergeResult) Saved() int64 {
+	return blobsSize(r.Linked) + blobsSize(r.Deduplicated)
+}
+
+func blobsSize(blobs []Blob) int64 {
+	var size int64
+	for _, blob := range blobs {
+		size += blob.Size
+	}
+	return size
+}
+
+// Merge adds the layouts in the directories srcs to dst. Every blob of the
+// sources is added, unless dst already has it, and the entries of their
+// index.json are added in order, once all blobs are.
+//
+// Entries without AnnotationRefName are added unless identical ones exist.
+// Entries with an AnnotationRefName are added as a group, which is skipped if
+// dst has the same entries with that name, and resolved with opts.Conflict if
+// it has other ones.
+func Merge(ctx context.Context, dst *Writer, srcs []string, opts MergeOptions) (MergeResult, error) {
+	var result MergeResult
+	indexes := make([]v1.Index, len(srcs))
+	for i, dir := range srcs {
+		src, err := Open(os.DirFS(dir), dst.opts)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", dir, err)
+		}
+		if indexes[i], err = src.Index(); err != nil {
+			return result, fmt.Errorf("%s: %w", dir, err)
+		}
+		if err := dst.mergeBlobs(ctx, &result, src, dir, opts.Link); err != nil {
+			return result, fmt.Errorf("%s: %w", dir, err)
+		}
+	}
+
+	policy := opts.Conflict
+	if policy == nil {
+		policy = FailOnConflict
+	}
+	err := dst.UpdateIndex(func(index *v1.Index) error {
+		for i, src := range indexes {
+			if err := mergeIndex(&result, index, src, srcs[i], policy); err != nil {
+				return fmt.Errorf("%s: %w", srcs[i], err)
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// mergeBlobs adds the blobs of src, in the directory dir, to w.
+func (w *Writer) mergeBlobs(ctx context.Context, result *MergeResult, src *Reader, dir string, mode LinkMode) error {
+	blobs, err := src.blobs()
+	if err != nil {
+		return err
+	}
+	for _, blob := range blobs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		desc := v1.Descriptor{Digest: blob.Digest, Size: blob.Size}
+		exists, err := w.Exists(ctx, desc)
+		if err != nil {
+			return err
+		}
+		if exists {
+			result.Deduplicated = append(result.Deduplicated, blob.Blob)
+			continue
+		}
+		if mode != CopyBlobs {
+			linked, err := w.link(filepath.Join(dir, filepath.FromSlash(blob.path)), desc, mode)
+			if err != nil {
+				return err
+			}
+			if linked {
+				result.Linked = append(result.Linked, blob.Blob)
+				continue
+			}
+		}
+		rc, err := src.Fetch(ctx, desc)
+		if err != nil {
+			return err
+		}
+		err = w.Push(ctx, desc, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		result.Copied = append(result.Copied, blob.Blob)
+	}
+	return nil
+}
+
+// link adds the blob of desc as a link of the file src, verifying it. It
+// returns false if src cannot be linked with mode.
+func (w *Writer) link(src string, desc v1.Descriptor, mode LinkMode) (bool, error) {
+	name, err := BlobPath(desc.Digest)
+	if err != nil {
+		return false, err
+	}
+	path := filepath.Join(w.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), tempPrefix+desc.Digest.Encoded()+"-*")
+	if err != nil {
+		return false, err
+	}
+
+	if mode == HardlinkBlobs {
+		// the temporary file only reserves a name for the link
+		f.Close()
+		os.Remove(f.Name())
+		if err := os.Link(src, f.Name()); err != nil {
+			return false, nil
+		}
+		err := verifyFile(f.Name(), desc, w.opts.AlgorithmPolicy)
+		if err == nil {
+			touch(f.Name())
+			err = os.Rename(f.Name(), path)
+		}
+		if err != nil {
+			os.Remove(f.Name())
+			return false, err
+		}
+		return true, nil
+	}
+
+	s, err := os.Open(src)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return false, err
+	}
+	defer s.Close()
+	if err := reflink(f, s); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return false, nil
+	}
+	err = identity.VerifyDescriptor(desc, io.LimitReader(f, desc.Size+1), w.opts.AlgorithmPolicy)
+	return true, commit(f, path, err)
+}
+
+// verifyFile verifies that the file name has the content of desc.
+func verifyFile(name string, desc v1.Descriptor, policy identity.AlgorithmPolicy) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return identity.VerifyDescriptor(desc, io.LimitReader(f, desc.Size+1), policy)
+}
+
+// mergeIndex adds the entries of the index src, of the layout in the
+// directory dir, to index.
+func mergeIndex(result *MergeResult, index *v1.Index, src v1.Index, dir string, policy ConflictPolicy) error {
+	var names []string
+	groups := map[string][]v1.Descriptor{}
+	for _, desc := range src.Manifests {
+		name, ok := desc.Annotations[v1.AnnotationRefName]
+		if !ok {
+			if !containsDescriptor(index.Manifests, desc) {
+				index.Manifests = append(index.Manifests, desc)
+			}
+			continue
+		}
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], desc)
+	}
+
+	taken := func(name string) bool {
+		if _, ok := groups[name]; ok {
+			return true
+		}
+		for _, desc := range index.Manifests {
+			if desc.Annotations[v1.AnnotationRefName] == name {
+				return true
+			}
+		}
+		return false
+	}
+	for _, name := range names {
+		group := groups[name]
+		var existing []v1.Descriptor
+		for _, desc := range index.Manifests {
+			if desc.Annotations[v1.AnnotationRefName] == name {
+				existing = append(existing, desc)
+			}
+		}
+		if len(existing) > 0 {
+			if sameDescriptors(existing, group) {
+				continue
+			}
+			resolved, err := policy(name, taken)
+			if err != nil {
+				return err
+			}
+			if err := ValidateRefName(resolved); err != nil {
+				return err
+			}
+			if resolved == name {
+				result.Replaced = append(result.Replaced, name)
+			} else {
+				result.Renamed = append(result.Renamed, RefRename{Source: dir, From: name, To: resolved})
+				for i := range group {
+					group[i].Annotations = withAnnotation(group[i].Annotations, v1.AnnotationRefName, resolved)
+				}
+			}
+			index.Manifests = removeRef(index.Manifests, resolved)
+		}
+		index.Manifests = append(index.Manifests, group...)
+	}
+	return nil
+}
+
+// sameDescriptors returns whether a and b have the same descriptors.
+func sameDescriptors(a, b []v1.Descriptor) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, desc := range b {
+		if !containsDescriptor(a, desc) {
+			return false
+		}
+	}
+	return true
+}
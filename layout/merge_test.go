@@ -0,0 +1,222 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/builder"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// mergeSources are two layouts with images tagged "latest", and a shared
+// image tagged "common".
+type mergeSources struct {
+	dirs          []string
+	a, b, common  v1.Descriptor
+	blobs, shared []digest.Digest
+}
+
+func newMergeSources(t *testing.T) mergeSources {
+	t.Helper()
+	ctx := context.Background()
+	var s mergeSources
+	for i, own := range []string{"a", "b"} {
+		w, err := Create(t.TempDir(), Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		config := pushBlob(t, w, v1.MediaTypeImageConfig, []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`))
+		shared := pushBlob(t, w, v1.MediaTypeImageLayer, []byte("shared layer"))
+		layer := pushBlob(t, w, v1.MediaTypeImageLayer, []byte("layer "+own))
+		image := pushManifest(t, w, builder.NewManifest().Validate(builder.NoValidation).ConfigDescriptor(config).LayerDescriptor(shared).LayerDescriptor(layer))
+		common := pushManifest(t, w, builder.NewManifest().Validate(builder.NoValidation).ConfigDescriptor(config).LayerDescriptor(shared))
+		if err := w.Tag(ctx, image, "latest"); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Tag(ctx, common, "common"); err != nil {
+			t.Fatal(err)
+		}
+		s.dirs = append(s.dirs, w.Dir())
+		if i == 0 {
+			s.a = image
+			s.blobs = []digest.Digest{config.Digest, shared.Digest, layer.Digest, image.Digest, common.Digest}
+		} else {
+			s.b = image
+			s.blobs = append(s.blobs, layer.Digest, image.Digest)
+			s.shared = []digest.Digest{config.Digest, shared.Digest, common.Digest}
+			// an untagged entry is added as is
+			if err := w.Add(ctx, image); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s.common = common
+	}
+	return s
+}
+
+func blobDigests(blobs []Blob) []digest.Digest {
+	var digests []digest.Digest
+	for _, blob := range blobs {
+		digests = append(digests, blob.Digest)
+	}
+	return sortedDigests(digests...)
+}
+
+func checkResolve(t *testing.T, w *Writer, reference string, expected digest.Digest) {
+	t.Helper()
+	desc, err := w.Resolve(context.Background(), reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Digest != expected {
+		t.Errorf("%s resolved to %s, expected %s", reference, desc.Digest, expected)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	ctx := context.Background()
+	s := newMergeSources(t)
+	newDst := func() *Writer {
+		w, err := Create(t.TempDir(), Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return w
+	}
+
+	t.Run("rename", func(t *testing.T) {
+		w := newDst()
+		result, err := Merge(ctx, w, s.dirs, MergeOptions{Conflict: RenameOnConflict})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := blobDigests(result.Copied); !equalDigests(got, sortedDigests(s.blobs...)) {
+			t.Errorf("copied %v, expected %v", got, s.blobs)
+		}
+		if got := blobDigests(result.Deduplicated); !equalDigests(got, sortedDigests(s.shared...)) {
+			t.Errorf("deduplicated %v, expected %v", got, s.shared)
+		}
+		if result.Saved() != blobsSize(result.Deduplicated) || result.Saved() == 0 || result.Written() == 0 {
+			t.Errorf("unexpected sizes: %d written, %d saved", result.Written(), result.Saved())
+		}
+		if len(result.Renamed) != 1 || result.Renamed[0] != (RefRename{Source: s.dirs[1], From: "latest", To: "latest-2"}) {
+			t.Errorf("unexpected renames %v", result.Renamed)
+		}
+		checkResolve(t, w, "latest", s.a.Digest)
+		checkResolve(t, w, "latest-2", s.b.Digest)
+		checkResolve(t, w, "common", s.common.Digest)
+		index, err := w.Index()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(index.Manifests) != 4 {
+			t.Errorf("unexpected entries %v", index.Manifests)
+		}
+		report, err := Fsck(ctx, w.Dir(), FsckOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !report.OK() {
+			t.Errorf("unexpected issues %v", report.Issues)
+		}
+
+		// merging a source again only deduplicates its five blobs
+		result, err = Merge(ctx, w, s.dirs[:1], MergeOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Copied) != 0 || len(result.Renamed) != 0 || len(result.Deduplicated) != 5 {
+			t.Errorf("unexpected result of a second merge: %+v", result)
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		w := newDst()
+		_, err := Merge(ctx, w, s.dirs, MergeOptions{})
+		if !errors.Is(err, ErrRefConflict) {
+			t.Fatalf("expected a conflict, got %v", err)
+		}
+		index, err := w.Index()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(index.Manifests) != 0 {
+			t.Errorf("index.json updated after a conflict: %v", index.Manifests)
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		w := newDst()
+		result, err := Merge(ctx, w, s.dirs, MergeOptions{Conflict: OverwriteOnConflict})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Replaced) != 1 || result.Replaced[0] != "latest" {
+			t.Errorf("unexpected replaced names %v", result.Replaced)
+		}
+		checkResolve(t, w, "latest", s.b.Digest)
+	})
+
+	t.Run("hardlink", func(t *testing.T) {
+		w := newDst()
+		result, err := Merge(ctx, w, s.dirs, MergeOptions{Link: HardlinkBlobs, Conflict: RenameOnConflict})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := blobDigests(result.Linked); !equalDigests(got, sortedDigests(s.blobs...)) {
+			t.Errorf("linked %v, expected %v", got, s.blobs)
+		}
+		if result.Saved() != blobsSize(result.Linked)+blobsSize(result.Deduplicated) || result.Written() != 0 {
+			t.Errorf("unexpected sizes: %d written, %d saved", result.Written(), result.Saved())
+		}
+		name, err := BlobPath(s.a.Digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src, err := os.Stat(filepath.Join(s.dirs[0], name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst, err := os.Stat(filepath.Join(w.Dir(), name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !os.SameFile(src, dst) {
+			t.Errorf("blob %s is not linked", s.a.Digest)
+		}
+		checkNoTempFiles(t, w.Dir())
+	})
+
+	t.Run("reflink", func(t *testing.T) {
+		// reflinks fall back to copies on most file systems
+		w := newDst()
+		result, err := Merge(ctx, w, s.dirs, MergeOptions{Link: ReflinkBlobs, Conflict: RenameOnConflict})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := blobDigests(append(result.Linked, result.Copied...))
+		if !equalDigests(got, sortedDigests(s.blobs...)) {
+			t.Errorf("added %v, expected %v", got, s.blobs)
+		}
+		checkBlobs(t, w, true, s.blobs...)
+		checkNoTempFiles(t, w.Dir())
+	})
+}
@@ -0,0 +1,252 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/content"
+	"github.com/opencontainers/image-spec/identity"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ErrUnsupportedVersion is returned when opening a layout whose oci-layout
+// file does not have ImageLayoutVersion.
+var ErrUnsupportedVersion = errors.New("unsupported image layout version")
+
+// maxIndexSize is the maximum size of index.json and oci-layout.
+const maxIndexSize = 4 << 20
+
+// Options are the options of a layout.
+type Options struct {
+	// AlgorithmPolicy decides whether blobs whose digest has an unknown
+	// algorithm are accepted, in which case only their size is verified. A
+	// nil policy rejects them.
+	AlgorithmPolicy identity.AlgorithmPolicy
+}
+
+// Reader reads an image layout. It implements content.Fetcher,
+// content.Exister, content.Resolver and content.TagLister.
+type Reader struct {
+	fsys fs.FS
+	opts Options
+
+	mu      sync.Mutex
+	scanned map[digest.Digest]scannedBlob // by referrerBlobs
+}
+
+var (
+	_ content.Fetcher   = (*Reader)(nil)
+	_ content.Exister   = (*Reader)(nil)
+	_ content.Resolver  = (*Reader)(nil)
+	_ content.TagLister = (*Reader)(nil)
+)
+
+// Open returns a Reader for the layout at the root of fsys, such as an
+// os.DirFS, after checking its oci-layout file.
+func Open(fsys fs.FS, opts Options) (*Reader, error) {
+	raw, err := readFile(fsys, v1.ImageLayoutFile)
+	if err != nil {
+		return nil, err
+	}
+	var layout v1.ImageLayout
+	if err := json.Unmarshal(raw, &layout); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", v1.ImageLayoutFile, err)
+	}
+	if layout.Version != v1.ImageLayoutVersion {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedVersion, layout.Version)
+	}
+	return &Reader{fsys: fsys, opts: opts}, nil
+}
+
+// Index returns the index of the layout, read from index.json.
+func (r *Reader) Index() (v1.Index, error) {
+	raw, err := readFile(r.fsys, v1.ImageIndexFile)
+	if err != nil {
+		return v1.Index{}, err
+	}
+	return parseIndex(raw)
+}
+
+func parseIndex(raw []byte) (v1.Index, error) {
+	var index v1.Index
+	if err := json.Unmarshal(raw, &index); err != nil {
+		return v1.Index{}, fmt.Errorf("parsing %s: %w", v1.ImageIndexFile, err)
+	}
+	if index.SchemaVersion != 2 {
+		return v1.Index{}, fmt.Errorf("%s has unsupported schemaVersion %d", v1.ImageIndexFile, index.SchemaVersion)
+	}
+	if index.MediaType != "" && index.MediaType != v1.MediaTypeImageIndex {
+		return v1.Index{}, fmt.Errorf("%s has unexpected mediaType %q", v1.ImageIndexFile, index.MediaType)
+	}
+	return index, nil
+}
+
+// Lookup returns the descriptors of index.json whose AnnotationRefName is
+// refName, in order. Entries without an AnnotationRefName never match, even
+// if refName is empty.
+func (r *Reader) Lookup(refName string) ([]v1.Descriptor, error) {
+	index, err := r.Index()
+	if err != nil {
+		return nil, err
+	}
+	var descs []v1.Descriptor
+	for _, desc := range index.Manifests {
+		if name, ok := desc.Annotations[v1.AnnotationRefName]; ok && name == refName {
+			descs = append(descs, desc)
+		}
+	}
+	return descs, nil
+}
+
+// Tags returns the distinct AnnotationRefName of the entries of index.json,
+// sorted.
+func (r *Reader) Tags(context.Context) ([]string, error) {
+	index, err := r.Index()
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	seen := map[string]bool{}
+	for _, desc := range index.Manifests {
+		tag, ok := desc.Annotations[v1.AnnotationRefName]
+		if ok && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// Resolve returns the descriptor of index.json whose AnnotationRefName is
+// reference. It fails with an error wrapping ErrAmbiguousReference if there
+// are several distinct ones.
+func (r *Reader) Resolve(_ context.Context, reference string) (v1.Descriptor, error) {
+	descs, err := r.Lookup(reference)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	if len(descs) == 0 {
+		return v1.Descriptor{}, fmt.Errorf("%w: reference %q", content.ErrNotFound, reference)
+	}
+	for _, desc := range descs[1:] {
+		if desc.Digest != descs[0].Digest {
+			return v1.Descriptor{}, fmt.Errorf("%w: reference %q matches %d descriptors", ErrAmbiguousReference, reference, len(descs))
+		}
+	}
+	return descs[0], nil
+}
+
+// Fetch returns a reader for the blob of desc. The reader verifies the size
+// and digest of the content, and returns an error instead of io.EOF if they
+// do not match desc.
+func (r *Reader) Fetch(_ context.Context, desc v1.Descriptor) (io.ReadCloser, error) {
+	verifiable, err := identity.CheckAlgorithm(desc.Digest, r.opts.AlgorithmPolicy)
+	if err != nil {
+		return nil, err
+	}
+	name, err := BlobPath(desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+	f, err := r.fsys.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", content.ErrNotFound, desc.Digest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	vr := &verifyingReader{rc: f, r: io.LimitReader(f, desc.Size+1), desc: desc}
+	if verifiable {
+		vr.verifier = desc.Digest.Verifier()
+	}
+	return vr, nil
+}
+
+// Exists returns whether the blob of desc is in the layout, with the size
+// of desc. Its content is not verified.
+func (r *Reader) Exists(_ context.Context, desc v1.Descriptor) (bool, error) {
+	name, err := BlobPath(desc.Digest)
+	if err != nil {
+		return false, err
+	}
+	fi, err := fs.Stat(r.fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return fi.Mode().IsRegular() && fi.Size() == desc.Size, nil
+}
+
+// verifyingReader reads content, checking that it matches desc at io.EOF.
+type verifyingReader struct {
+	rc       io.ReadCloser
+	r        io.Reader
+	desc     v1.Descriptor
+	verifier digest.Verifier // nil for unknown algorithms
+	n        int64
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	v.n += int64(n)
+	if v.n > v.desc.Size {
+		return 0, fmt.Errorf("content %s has unexpected size", v.desc.Digest)
+	}
+	if v.verifier != nil {
+		v.verifier.Write(p[:n])
+	}
+	if errors.Is(err, io.EOF) {
+		if v.n != v.desc.Size {
+			return n, fmt.Errorf("content %s has unexpected size", v.desc.Digest)
+		}
+		if v.verifier != nil && !v.verifier.Verified() {
+			return n, fmt.Errorf("content %s does not match its digest", v.desc.Digest)
+		}
+	}
+	return n, err
+}
+
+func (v *verifyingReader) Close() error {
+	return v.rc.Close()
+}
+
+// readFile reads the small metadata file name of fsys.
+func readFile(fsys fs.FS, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	raw, err := io.ReadAll(io.LimitReader(f, maxIndexSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	if len(raw) > maxIndexSize {
+		return nil, fmt.Errorf("%s is too large", name)
+	}
+	return raw, nil
+}
@@ -0,0 +1,208 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/content"
+	"github.com/opencontainers/image-spec/identity"
+	"github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// testFS returns a layout tagging blobs with the given ref names, the
+// descriptors of blobs in order.
+func testFS(t *testing.T, refNames []string, blobs ...[]byte) (fstest.MapFS, []v1.Descriptor) {
+	t.Helper()
+	fsys := fstest.MapFS{
+		v1.ImageLayoutFile: {Data: []byte(`{"imageLayoutVersion":"1.0.0"}`)},
+	}
+	index := v1.Index{Versioned: specs.Versioned{SchemaVersion: 2}, MediaType: v1.MediaTypeImageIndex}
+	var descs []v1.Descriptor
+	for i, blob := range blobs {
+		desc := v1.Descriptor{MediaType: v1.MediaTypeImageManifest, Digest: digest.FromBytes(blob), Size: int64(len(blob))}
+		name, err := BlobPath(desc.Digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fsys[name] = &fstest.MapFile{Data: blob}
+		descs = append(descs, desc)
+		if i < len(refNames) {
+			desc.Annotations = map[string]string{v1.AnnotationRefName: refNames[i]}
+		}
+		index.Manifests = append(index.Manifests, desc)
+	}
+	raw, err := json.Marshal(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys[v1.ImageIndexFile] = &fstest.MapFile{Data: raw}
+	return fsys, descs
+}
+
+func TestReader(t *testing.T) {
+	ctx := context.Background()
+	fsys, descs := testFS(t, []string{"v1", "v2", "v1"}, []byte(`{"first":1}`), []byte(`{"second":2}`), []byte(`{"third":3}`), []byte(`{"untagged":4}`))
+	r, err := Open(fsys, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := r.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Manifests) != 4 {
+		t.Errorf("unexpected index: %v", index)
+	}
+
+	desc, err := r.Resolve(ctx, "v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Digest != descs[1].Digest {
+		t.Errorf("unexpected descriptor for v2: %v", desc)
+	}
+	if _, err := r.Resolve(ctx, "v1"); !errors.Is(err, ErrAmbiguousReference) {
+		t.Errorf("expected ambiguous reference, got %v", err)
+	}
+	if _, err := r.Resolve(ctx, "v3"); !errors.Is(err, content.ErrNotFound) {
+		t.Errorf("unexpected error for missing reference: %v", err)
+	}
+	if found, err := r.Lookup("v1"); err != nil || len(found) != 2 {
+		t.Errorf("unexpected lookup of v1: %v, %v", found, err)
+	}
+	// untagged entries have no reference, not an empty one
+	if found, err := r.Lookup(""); err != nil || len(found) != 0 {
+		t.Errorf("unexpected lookup of an empty reference: %v, %v", found, err)
+	}
+	if _, err := r.Resolve(ctx, ""); !errors.Is(err, content.ErrNotFound) {
+		t.Errorf("unexpected error for an empty reference: %v", err)
+	}
+	if tags, err := r.Tags(ctx); err != nil || fmt.Sprint(tags) != "[v1 v2]" {
+		t.Errorf("unexpected tags: %v, %v", tags, err)
+	}
+
+	blob, err := content.ReadAll(ctx, r, descs[0], 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(blob) != `{"first":1}` {
+		t.Errorf("unexpected blob: %s", blob)
+	}
+	if _, err := r.Fetch(ctx, v1.Descriptor{Digest: digest.FromString("missing"), Size: 7}); !errors.Is(err, content.ErrNotFound) {
+		t.Errorf("unexpected error for missing blob: %v", err)
+	}
+}
+
+func TestReaderVerification(t *testing.T) {
+	ctx := context.Background()
+	fsys, descs := testFS(t, nil, []byte("content"))
+	name, err := BlobPath(descs[0].Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown := v1.Descriptor{Digest: "unknown:0123456789abcdef", Size: 7}
+	unknownName, err := BlobPath(unknown.Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys[unknownName] = &fstest.MapFile{Data: []byte("content")}
+
+	for _, tt := range []struct {
+		name   string
+		data   string
+		desc   v1.Descriptor
+		policy identity.AlgorithmPolicy
+		valid  bool
+	}{
+		{name: "valid", data: "content", desc: descs[0], valid: true},
+		{name: "corrupt", data: "CONTENT", desc: descs[0]},
+		{name: "truncated", data: "conten", desc: descs[0]},
+		{name: "too long", data: "content!", desc: descs[0]},
+		{name: "unknown algorithm", data: "content", desc: unknown},
+		{name: "accepted unknown algorithm", data: "content", desc: unknown, policy: identity.AcceptUnknownAlgorithms, valid: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys[name] = &fstest.MapFile{Data: []byte(tt.data)}
+			r, err := Open(fsys, Options{AlgorithmPolicy: tt.policy})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = readBlob(ctx, r, tt.desc)
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func readBlob(ctx context.Context, r *Reader, desc v1.Descriptor) error {
+	rc, err := r.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	_, err = io.Copy(io.Discard, rc)
+	return err
+}
+
+func TestOpenInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		fsys  fstest.MapFS
+		index bool
+	}{
+		{name: "missing oci-layout", fsys: fstest.MapFS{}},
+		{name: "unsupported version", fsys: fstest.MapFS{v1.ImageLayoutFile: {Data: []byte(`{"imageLayoutVersion":"2.0.0"}`)}}},
+		{name: "malformed oci-layout", fsys: fstest.MapFS{v1.ImageLayoutFile: {Data: []byte(`{`)}}},
+		{name: "missing index", index: true, fsys: fstest.MapFS{v1.ImageLayoutFile: {Data: []byte(`{"imageLayoutVersion":"1.0.0"}`)}}},
+		{name: "index schemaVersion", index: true, fsys: fstest.MapFS{
+			v1.ImageLayoutFile: {Data: []byte(`{"imageLayoutVersion":"1.0.0"}`)},
+			v1.ImageIndexFile:  {Data: []byte(`{"schemaVersion":1,"manifests":[]}`)},
+		}},
+		{name: "index mediaType", index: true, fsys: fstest.MapFS{
+			v1.ImageLayoutFile: {Data: []byte(`{"imageLayoutVersion":"1.0.0"}`)},
+			v1.ImageIndexFile:  {Data: []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","manifests":[]}`)},
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Open(tt.fsys, Options{})
+			if !tt.index {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.Index(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
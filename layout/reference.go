@@ -0,0 +1,218 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/content"
+	"github.com/opencontainers/image-spec/platform"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var (
+	// ErrInvalidReference is returned when parsing an invalid reference.
+	ErrInvalidReference = errors.New("invalid reference")
+
+	// ErrAmbiguousReference is returned when a reference matches several
+	// manifests, and none can be selected.
+	ErrAmbiguousReference = errors.New("ambiguous reference")
+)
+
+// refNameRegexp is the grammar of AnnotationRefName, in annotations.md.
+var refNameRegexp = regexp.MustCompile(`^[A-Za-z0-9]+(?:(?:[-._:@+]|--)[A-Za-z0-9]+)*(?:/[A-Za-z0-9]+(?:(?:[-._:@+]|--)[A-Za-z0-9]+)*)*$`)
+
+// ValidateRefName returns an error wrapping ErrInvalidReference if name does
+// not match the grammar of AnnotationRefName.
+func ValidateRefName(name string) error {
+	if !refNameRegexp.MatchString(name) {
+		return fmt.Errorf("%w: ref name %q", ErrInvalidReference, name)
+	}
+	return nil
+}
+
+// Reference is a reference to a manifest in a layout, of the form
+// "<dir>:<tag>", "<dir>@<digest>" or "<dir>:<tag>@<digest>".
+type Reference struct {
+	// Dir is the directory of the layout.
+	Dir string
+
+	// Tag is the AnnotationRefName of the manifest, if any.
+	Tag string
+
+	// Digest is the digest of the manifest, if any.
+	Digest digest.Digest
+}
+
+// ParseReference parses a reference to a manifest in a layout. The digest
+// is the part after the last "@" if it is a valid digest, and the tag is
+// the part after the first ":" of the rest, so the directory cannot have a
+// ":", while the tag may have ":" and "@". At least a tag or a digest is
+// required.
+func ParseReference(s string) (Reference, error) {
+	var ref Reference
+	rest := s
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		d := digest.Digest(s[i+1:])
+		if err := d.Validate(); err == nil || errors.Is(err, digest.ErrDigestUnsupported) {
+			ref.Digest = d
+			rest = s[:i]
+		}
+	}
+	dir, tag, hasTag := strings.Cut(rest, ":")
+	ref.Dir = dir
+	switch {
+	case dir == "":
+		return Reference{}, fmt.Errorf("%w: %q has no layout directory", ErrInvalidReference, s)
+	case hasTag:
+		if err := ValidateRefName(tag); err != nil {
+			return Reference{}, err
+		}
+		ref.Tag = tag
+	case ref.Digest == "":
+		return Reference{}, fmt.Errorf("%w: %q has no tag or digest", ErrInvalidReference, s)
+	}
+	return ref, nil
+}
+
+func (r Reference) String() string {
+	s := r.Dir
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest.String()
+	}
+	return s
+}
+
+// Match returns the entries of index.json matching the tag and digest of
+// ref, ignoring its directory, failing with an error wrapping
+// content.ErrNotFound if there are none. When ref has only a digest that
+// is not in index.json, the manifest blob itself is matched, with the media
+// type of its content.
+func (r *Reader) Match(ctx context.Context, ref Reference) ([]v1.Descriptor, error) {
+	index, err := r.Index()
+	if err != nil {
+		return nil, err
+	}
+	var descs []v1.Descriptor
+	for _, desc := range index.Manifests {
+		if ref.Tag != "" && desc.Annotations[v1.AnnotationRefName] != ref.Tag {
+			continue
+		}
+		if ref.Digest != "" && desc.Digest != ref.Digest {
+			continue
+		}
+		descs = append(descs, desc)
+	}
+	if len(descs) > 0 {
+		return descs, nil
+	}
+	if ref.Tag == "" && ref.Digest != "" {
+		desc, err := r.manifestBlob(ctx, ref.Digest)
+		if err != nil {
+			return nil, err
+		}
+		return []v1.Descriptor{desc}, nil
+	}
+	return nil, fmt.Errorf("%w: reference %q", content.ErrNotFound, ref)
+}
+
+// manifestBlob returns the descriptor of the manifest or index blob with
+// the digest d.
+func (r *Reader) manifestBlob(ctx context.Context, d digest.Digest) (v1.Descriptor, error) {
+	name, err := BlobPath(d)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	fi, err := fs.Stat(r.fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return v1.Descriptor{}, fmt.Errorf("%w: %s", content.ErrNotFound, d)
+	}
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	desc := v1.Descriptor{Digest: d, Size: fi.Size()}
+	blob, err := content.ReadAll(ctx, r, desc, maxIndexSize)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	var header struct {
+		MediaType string `json:"mediaType"`
+	}
+	if err := json.Unmarshal(blob, &header); err != nil {
+		return v1.Descriptor{}, fmt.Errorf("blob %s is not a manifest: %w", d, err)
+	}
+	if !isManifest(header.MediaType) && !isIndex(header.MediaType) {
+		return v1.Descriptor{}, fmt.Errorf("blob %s is not a manifest: media type %q", d, header.MediaType)
+	}
+	desc.MediaType = header.MediaType
+	return desc, nil
+}
+
+// ResolveReference returns the manifest ref resolves to. When it matches
+// several manifests, only those for the platform want, as matched by
+// platform.Match, or without a platform, are considered if want is not nil.
+// If there are still several to choose from, it fails with an error wrapping
+// ErrAmbiguousReference.
+func (r *Reader) ResolveReference(ctx context.Context, ref Reference, want *v1.Platform) (v1.Descriptor, error) {
+	descs, err := r.Match(ctx, ref)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	if distinctDigests(descs) && want != nil {
+		var matched []v1.Descriptor
+		for _, desc := range descs {
+			if desc.Platform == nil || platform.Match(*want, *desc.Platform) {
+				matched = append(matched, desc)
+			}
+		}
+		if len(matched) == 0 {
+			return v1.Descriptor{}, fmt.Errorf("%w: reference %q for platform %s/%s", content.ErrNotFound, ref, want.OS, want.Architecture)
+		}
+		descs = matched
+	}
+	if distinctDigests(descs) {
+		var platforms []string
+		for _, desc := range descs {
+			if desc.Platform != nil {
+				platforms = append(platforms, desc.Platform.OS+"/"+desc.Platform.Architecture)
+			}
+		}
+		if len(platforms) == len(descs) {
+			return v1.Descriptor{}, fmt.Errorf("%w: reference %q matches the platforms %s", ErrAmbiguousReference, ref, strings.Join(platforms, ", "))
+		}
+		return v1.Descriptor{}, fmt.Errorf("%w: reference %q matches %d manifests", ErrAmbiguousReference, ref, len(descs))
+	}
+	return descs[0], nil
+}
+
+// distinctDigests returns whether descs have several digests.
+func distinctDigests(descs []v1.Descriptor) bool {
+	for _, desc := range descs[1:] {
+		if desc.Digest != descs[0].Digest {
+			return true
+		}
+	}
+	return false
+}
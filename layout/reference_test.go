@@ -0,0 +1,153 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/builder"
+	"github.com/opencontainers/image-spec/content"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestParseReference(t *testing.T) {
+	d := digest.FromString("foo")
+	for _, tt := range []struct {
+		ref      string
+		expected Reference
+		invalid  bool
+	}{
+		{ref: "dir:latest", expected: Reference{Dir: "dir", Tag: "latest"}},
+		{ref: "path/to/dir:example.com/app:v1.0", expected: Reference{Dir: "path/to/dir", Tag: "example.com/app:v1.0"}},
+		{ref: "dir@" + d.String(), expected: Reference{Dir: "dir", Digest: d}},
+		{ref: "dir:v1@" + d.String(), expected: Reference{Dir: "dir", Tag: "v1", Digest: d}},
+		{ref: "dir:a@b", expected: Reference{Dir: "dir", Tag: "a@b"}},
+		{ref: "dir:a--b+c", expected: Reference{Dir: "dir", Tag: "a--b+c"}},
+		{ref: "dir", invalid: true},
+		{ref: ":latest", invalid: true},
+		{ref: "dir:", invalid: true},
+		{ref: "dir:-latest", invalid: true},
+		{ref: "dir:a//b", invalid: true},
+		{ref: "dir:a---b", invalid: true},
+		{ref: "dir:a b", invalid: true},
+		{ref: "dir@", invalid: true},
+	} {
+		ref, err := ParseReference(tt.ref)
+		switch {
+		case tt.invalid:
+			if !errors.Is(err, ErrInvalidReference) {
+				t.Errorf("%q: expected invalid reference, got %+v, %v", tt.ref, ref, err)
+			}
+		case err != nil:
+			t.Errorf("%q: %v", tt.ref, err)
+		case ref != tt.expected:
+			t.Errorf("%q: got %+v, expected %+v", tt.ref, ref, tt.expected)
+		case ref.String() != tt.ref:
+			t.Errorf("%q: formatted as %q", tt.ref, ref.String())
+		}
+	}
+}
+
+func TestResolveReference(t *testing.T) {
+	ctx := context.Background()
+	w, err := Create(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := pushBlob(t, w, v1.MediaTypeImageConfig, []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`))
+	amd64 := pushManifest(t, w, builder.NewManifest().Validate(builder.NoValidation).ConfigDescriptor(config).LayerDescriptor(pushBlob(t, w, v1.MediaTypeImageLayer, []byte("amd64"))))
+	arm64 := pushManifest(t, w, builder.NewManifest().Validate(builder.NoValidation).ConfigDescriptor(config).LayerDescriptor(pushBlob(t, w, v1.MediaTypeImageLayer, []byte("arm64"))))
+	untagged := pushManifest(t, w, builder.NewManifest().Validate(builder.NoValidation).ConfigDescriptor(config))
+	amd64.Platform = &v1.Platform{OS: "linux", Architecture: "amd64"}
+	arm64.Platform = &v1.Platform{OS: "linux", Architecture: "arm64"}
+	for _, desc := range []v1.Descriptor{amd64, arm64} {
+		desc.Annotations = map[string]string{v1.AnnotationRefName: "multi"}
+		if err := w.Add(ctx, desc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Tag(ctx, amd64, "single"); err != nil {
+		t.Fatal(err)
+	}
+	dup := arm64
+	dup.Platform = nil
+	dup.Annotations = map[string]string{v1.AnnotationRefName: "dup"}
+	if err := w.Add(ctx, dup); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Add(ctx, withRefName(amd64, "dup")); err != nil {
+		t.Fatal(err)
+	}
+
+	linuxARM := &v1.Platform{OS: "linux", Architecture: "arm64"}
+	windows := &v1.Platform{OS: "windows", Architecture: "amd64"}
+	for _, tt := range []struct {
+		ref      string
+		platform *v1.Platform
+		expected digest.Digest
+		matches  int
+		err      error
+		fails    bool
+	}{
+		{ref: "dir:single", expected: amd64.Digest, matches: 1},
+		{ref: "dir:single", platform: windows, expected: amd64.Digest, matches: 1},
+		{ref: "dir:multi", matches: 2, err: ErrAmbiguousReference},
+		{ref: "dir:multi", platform: linuxARM, expected: arm64.Digest, matches: 2},
+		{ref: "dir:multi", platform: windows, matches: 2, err: content.ErrNotFound},
+		{ref: "dir:multi@" + amd64.Digest.String(), expected: amd64.Digest, matches: 1},
+		{ref: "dir:dup", matches: 2, err: ErrAmbiguousReference},
+		{ref: "dir:dup", platform: windows, expected: arm64.Digest, matches: 2},
+		{ref: "dir@" + arm64.Digest.String(), expected: arm64.Digest, matches: 2},
+		{ref: "dir@" + untagged.Digest.String(), expected: untagged.Digest, matches: 1},
+		{ref: "dir@" + config.Digest.String(), fails: true},
+		{ref: "dir@" + digest.FromString("missing").String(), err: content.ErrNotFound},
+		{ref: "dir:missing", err: content.ErrNotFound},
+		{ref: "dir:single@" + arm64.Digest.String(), err: content.ErrNotFound},
+	} {
+		ref, err := ParseReference(tt.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		descs, err := w.Match(ctx, ref)
+		if len(descs) != tt.matches {
+			t.Errorf("%s: %d matches, expected %d (%v)", tt.ref, len(descs), tt.matches, err)
+		}
+		desc, err := w.ResolveReference(ctx, ref, tt.platform)
+		switch {
+		case tt.err != nil:
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s: expected %v, got %v", tt.ref, tt.err, err)
+			}
+		case tt.fails:
+			if err == nil {
+				t.Errorf("%s: expected error, got %v", tt.ref, desc)
+			}
+		case err != nil:
+			t.Errorf("%s: %v", tt.ref, err)
+		case desc.Digest != tt.expected:
+			t.Errorf("%s: resolved to %s, expected %s", tt.ref, desc.Digest, tt.expected)
+		case desc.MediaType != v1.MediaTypeImageManifest:
+			t.Errorf("%s: unexpected media type %q", tt.ref, desc.MediaType)
+		}
+	}
+}
+
+func withRefName(desc v1.Descriptor, name string) v1.Descriptor {
+	desc.Annotations = map[string]string{v1.AnnotationRefName: name}
+	return desc
+}
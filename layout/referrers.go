@@ -0,0 +1,118 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/content"
+	"github.com/opencontainers/image-spec/referrers"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Referrers returns the descriptors of the manifests and indexes of the
+// layout whose subject is subject, as listed by the referrers API. Every
+// blob of the layout is a candidate, whether or not it is reachable from
+// index.json. Each blob is only read once by r, as blobs are immutable, so
+// that listing the referrers of many subjects does not read the whole layout
+// again for each of them.
+func (r *Reader) Referrers(ctx context.Context, subject v1.Descriptor) ([]v1.Descriptor, error) {
+	blobs, err := r.referrerBlobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []v1.Descriptor
+	for _, blob := range blobs {
+		if blob.subject == subject.Digest {
+			candidates = append(candidates, blob.desc)
+		}
+	}
+	index, err := referrers.Index(ctx, r, subject.Digest, candidates)
+	if err != nil {
+		return nil, err
+	}
+	return index.Manifests, nil
+}
+
+// referrerBlob is a manifest or index of the layout with a subject.
+type referrerBlob struct {
+	desc    v1.Descriptor
+	subject digest.Digest
+}
+
+// scannedBlob is the result of reading a blob for referrerBlobs.
+type scannedBlob struct {
+	size     int64
+	referrer *referrerBlob // nil if the blob is not a referrer
+}
+
+// referrerBlobs returns the manifests and indexes of the layout that have a
+// subject, sorted by digest. Blobs that cannot be read or verified are
+// ignored. The blobs read successfully are remembered in r.scanned.
+func (r *Reader) referrerBlobs(ctx context.Context) ([]referrerBlob, error) {
+	blobs, err := r.blobs()
+	if err != nil {
+		return nil, err
+	}
+	var found []referrerBlob
+	for _, blob := range blobs {
+		if blob.Size > maxManifestSize {
+			continue
+		}
+		r.mu.Lock()
+		scanned, ok := r.scanned[blob.Digest]
+		r.mu.Unlock()
+		if !ok || scanned.size != blob.Size {
+			scanned, err = r.scanBlob(ctx, blob.Blob)
+			if err != nil {
+				continue
+			}
+			r.mu.Lock()
+			if r.scanned == nil {
+				r.scanned = map[digest.Digest]scannedBlob{}
+			}
+			r.scanned[blob.Digest] = scanned
+			r.mu.Unlock()
+		}
+		if scanned.referrer != nil {
+			found = append(found, *scanned.referrer)
+		}
+	}
+	return found, nil
+}
+
+// scanBlob reads blob, returning its subject if it is a manifest or index
+// with one.
+func (r *Reader) scanBlob(ctx context.Context, blob Blob) (scannedBlob, error) {
+	desc := v1.Descriptor{Digest: blob.Digest, Size: blob.Size}
+	raw, err := content.ReadAll(ctx, r, desc, maxManifestSize)
+	if err != nil {
+		return scannedBlob{}, err
+	}
+	scanned := scannedBlob{size: blob.Size}
+	var header struct {
+		MediaType string         `json:"mediaType"`
+		Subject   *v1.Descriptor `json:"subject"`
+	}
+	if json.Unmarshal(raw, &header) != nil || header.Subject == nil ||
+		(!isIndex(header.MediaType) && !isManifest(header.MediaType)) {
+		return scanned, nil
+	}
+	desc.MediaType = header.MediaType
+	scanned.referrer = &referrerBlob{desc: desc, subject: header.Subject.Digest}
+	return scanned, nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestReferrers(t *testing.T) {
+	ctx := context.Background()
+	l := newGCLayout(t)
+	image, err := l.w.Resolve(ctx, "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := l.w.Referrers(ctx, image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Digest != l.referrer || found[0].ArtifactType != "application/example.signature" {
+		t.Errorf("unexpected referrers: %v", found)
+	}
+
+	if exists, err := l.w.Exists(ctx, image); err != nil || !exists {
+		t.Errorf("image does not exist: %v", err)
+	}
+	truncated := image
+	truncated.Size--
+	if exists, err := l.w.Exists(ctx, truncated); err != nil || exists {
+		t.Errorf("blob exists with another size: %v", err)
+	}
+	if _, err := l.w.Exists(ctx, v1.Descriptor{Digest: "sha256:x"}); err == nil {
+		t.Error("expected error for an invalid digest")
+	}
+}
+
+// countingFS counts the blob files opened in fsys.
+type countingFS struct {
+	fs.FS
+	mu     sync.Mutex
+	opened map[string]int
+}
+
+func (c *countingFS) Open(name string) (fs.File, error) {
+	if strings.HasPrefix(name, v1.ImageBlobsDir+"/") && strings.Count(name, "/") == 2 {
+		c.mu.Lock()
+		c.opened[name]++
+		c.mu.Unlock()
+	}
+	return c.FS.Open(name)
+}
+
+func TestReferrersReadOnce(t *testing.T) {
+	ctx := context.Background()
+	l := newGCLayout(t)
+	fsys := &countingFS{FS: os.DirFS(l.w.Dir()), opened: map[string]int{}}
+	r, err := Open(fsys, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	image, err := r.Resolve(ctx, "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		found, err := r.Referrers(ctx, image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != 1 || found[0].Digest != l.referrer {
+			t.Errorf("unexpected referrers: %v", found)
+		}
+	}
+	if len(fsys.opened) == 0 {
+		t.Fatal("no blob was read")
+	}
+	// the referrer itself is read again by referrers.Index for its
+	// annotations, once per call
+	referrer, err := BlobPath(l.referrer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, n := range fsys.opened {
+		if name != referrer && n != 1 {
+			t.Errorf("blob %s read %d times", name, n)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux && (386 || amd64 || arm || arm64 || riscv64 || s390x)
+
+package layout
+
+import (
+	"os"
+	"syscall"
+)
+
+// ficlone is the FICLONE ioctl request of linux/fs.h, for the architectures
+// where _IOW(0x94, 9, int) has this value.
+const ficlone = 0x40049409
+
+// reflink makes dst a clone of src, sharing its storage, which only some
+// file systems support.
+func reflink(dst, src *os.File) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dst.Fd(), ficlone, src.Fd())
+	if errno != 0 {
+		return os.NewSyscallError("ioctl FICLONE", errno)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux || !(386 || amd64 || arm || arm64 || riscv64 || s390x)
+
+package layout
+
+import (
+	"errors"
+	"os"
+)
+
+// reflink is not supported on this platform.
+func reflink(_, _ *os.File) error {
+	return errors.New("reflink is not supported")
+}
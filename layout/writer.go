@@ -0,0 +1,355 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/content"
+	"github.com/opencontainers/image-spec/identity"
+	"github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// LockFile is the file of the layout root locked while index.json is
+// updated, and while blobs are garbage collected.
+const LockFile = "index.json.lock"
+
+// tempPrefix is the prefix of the temporary files of a Writer, which are
+// renamed once complete.
+const tempPrefix = ".tmp-"
+
+// Writer writes to an image layout in a directory. Blobs are written to
+// temporary files and renamed once verified, and index.json is replaced
+// atomically, under a lock on LockFile, such that concurrent writers, in
+// this or other processes, do not corrupt the layout.
+//
+// Writer implements content.Store.
+type Writer struct {
+	*Reader
+	dir string
+}
+
+var _ content.Store = (*Writer)(nil)
+
+// Create returns a Writer for the layout in the directory dir. The directory,
+// oci-layout and an empty index.json are created if they do not exist, and
+// an existing layout is checked as by Open.
+func Create(dir string, opts Options) (*Writer, error) {
+	if err := os.MkdirAll(filepath.Join(dir, v1.ImageBlobsDir), 0o755); err != nil {
+		return nil, err
+	}
+	w := &Writer{Reader: &Reader{fsys: os.DirFS(dir), opts: opts}, dir: dir}
+
+	_, err := os.Stat(filepath.Join(dir, v1.ImageLayoutFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		raw, err := json.Marshal(v1.ImageLayout{Version: v1.ImageLayoutVersion})
+		if err != nil {
+			return nil, err
+		}
+		err = w.writeFile(v1.ImageLayoutFile, raw)
+		if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	if _, err := Open(w.fsys, opts); err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Index(); errors.Is(err, fs.ErrNotExist) {
+		err = w.UpdateIndex(func(*v1.Index) error { return nil })
+		if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Dir returns the directory of the layout.
+func (w *Writer) Dir() string {
+	return w.dir
+}
+
+// Lock takes the lock of the layout, which is held while index.json is
+// updated, and returns the function releasing it. It must not be called while
+// the lock is held, including from UpdateIndex.
+func (w *Writer) Lock() (unlock func() error, err error) {
+	return lockFile(filepath.Join(w.dir, LockFile))
+}
+
+// Push writes the blob read from r, verifying it against expected. Pushing a
+// blob that already exists with the expected size only refreshes its
+// modification time, for the grace period of GC.
+func (w *Writer) Push(_ context.Context, expected v1.Descriptor, r io.Reader) error {
+	verifiable, err := identity.CheckAlgorithm(expected.Digest, w.opts.AlgorithmPolicy)
+	if err != nil {
+		return err
+	}
+	name, err := BlobPath(expected.Digest)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(w.dir, filepath.FromSlash(name))
+	if fi, err := os.Stat(path); err == nil && fi.Mode().IsRegular() && fi.Size() == expected.Size {
+		touch(path)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), tempPrefix+expected.Digest.Encoded()+"-*")
+	if err != nil {
+		return err
+	}
+	dst := io.Writer(f)
+	var verifier digest.Verifier
+	if verifiable {
+		verifier = expected.Digest.Verifier()
+		dst = io.MultiWriter(f, verifier)
+	}
+	n, err := io.Copy(dst, io.LimitReader(r, expected.Size+1))
+	switch {
+	case err != nil:
+	case n != expected.Size:
+		err = fmt.Errorf("content %s has unexpected size", expected.Digest)
+	case verifier != nil && !verifier.Verified():
+		err = fmt.Errorf("content %s does not match its digest", expected.Digest)
+	}
+	return commit(f, path, err)
+}
+
+// Exists is Reader.Exists, also refreshing the modification time of the
+// blob of desc if it exists: a writer finding a blob is about to reference
+// it, and GC must keep it for its grace period.
+func (w *Writer) Exists(ctx context.Context, desc v1.Descriptor) (bool, error) {
+	exists, err := w.Reader.Exists(ctx, desc)
+	if exists {
+		if name, err := BlobPath(desc.Digest); err == nil {
+			touch(filepath.Join(w.dir, filepath.FromSlash(name)))
+		}
+	}
+	return exists, err
+}
+
+// touch refreshes the modification time of the file name, such that GC
+// keeps it for its grace period. It is best effort, as the file may belong to
+// another user.
+func touch(name string) {
+	now := time.Now()
+	_ = os.Chtimes(name, now, now)
+}
+
+// removeBlob removes the blob d, if it is in the layout. It is best effort,
+// for the blobs of a failed import.
+func (w *Writer) removeBlob(d digest.Digest) {
+	if name, err := BlobPath(d); err == nil {
+		_ = os.Remove(filepath.Join(w.dir, filepath.FromSlash(name)))
+	}
+}
+
+// commit closes the temporary file f and renames it to path if err is nil,
+// or removes it otherwise.
+func commit(f *os.File, path string, err error) error {
+	if err == nil {
+		err = f.Chmod(0o644)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// writeFile atomically replaces the file name of the layout root with data.
+func (w *Writer) writeFile(name string, data []byte) error {
+	f, err := os.CreateTemp(w.dir, tempPrefix+name+"-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return commit(f, filepath.Join(w.dir, name), err)
+}
+
+// UpdateIndex calls update with the current index of the layout, an empty
+// one if there is no index.json, and writes the index it leaves, unless update
+// fails. The layout is locked during the update.
+func (w *Writer) UpdateIndex(update func(index *v1.Index) error) (err error) {
+	unlock, err := w.Lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if uerr := unlock(); err == nil {
+			err = uerr
+		}
+	}()
+
+	index, err := w.Index()
+	if errors.Is(err, fs.ErrNotExist) {
+		index = v1.Index{Versioned: specs.Versioned{SchemaVersion: 2}, MediaType: v1.MediaTypeImageIndex}
+	} else if err != nil {
+		return err
+	}
+	if err := update(&index); err != nil {
+		return err
+	}
+	if index.Manifests == nil {
+		index.Manifests = []v1.Descriptor{}
+	}
+	raw, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return w.writeFile(v1.ImageIndexFile, raw)
+}
+
+// Tag adds desc to index.json with reference as its AnnotationRefName,
+// replacing the entries that had this reference. The blob of desc must be in
+// the layout, and reference must be valid as checked by ValidateRefName.
+func (w *Writer) Tag(_ context.Context, desc v1.Descriptor, reference string) error {
+	if err := ValidateRefName(reference); err != nil {
+		return err
+	}
+	desc.Annotations = withAnnotation(desc.Annotations, v1.AnnotationRefName, reference)
+	return w.UpdateIndex(func(index *v1.Index) error {
+		if err := w.checkBlob(desc); err != nil {
+			return err
+		}
+		manifests := removeRef(index.Manifests, reference)
+		index.Manifests = append(manifests, desc)
+		return nil
+	})
+}
+
+// Add adds desc to index.json, as is, unless an identical entry exists. The
+// blob of desc must be in the layout.
+//
+// Unlike Tag, entries with the same AnnotationRefName as desc are kept, as
+// for the images of different platforms sharing a reference.
+func (w *Writer) Add(_ context.Context, desc v1.Descriptor) error {
+	if err := checkRefName(desc); err != nil {
+		return err
+	}
+	return w.UpdateIndex(func(index *v1.Index) error {
+		if err := w.checkBlob(desc); err != nil {
+			return err
+		}
+		if !containsDescriptor(index.Manifests, desc) {
+			index.Manifests = append(index.Manifests, desc)
+		}
+		return nil
+	})
+}
+
+// Untag removes the entries of index.json whose AnnotationRefName is
+// reference, failing with an error wrapping content.ErrNotFound if there are
+// none. Their blobs are not removed.
+func (w *Writer) Untag(_ context.Context, reference string) error {
+	return w.UpdateIndex(func(index *v1.Index) error {
+		manifests := removeRef(index.Manifests, reference)
+		if len(manifests) == len(index.Manifests) {
+			return fmt.Errorf("%w: reference %q", content.ErrNotFound, reference)
+		}
+		index.Manifests = manifests
+		return nil
+	})
+}
+
+// checkBlob checks that the blob of desc is in the layout, with its size. It
+// is called with the lock held, such that the blob is not garbage collected
+// before its entry is added.
+func (w *Writer) checkBlob(desc v1.Descriptor) error {
+	name, err := BlobPath(desc.Digest)
+	if err != nil {
+		return err
+	}
+	fi, err := os.Stat(filepath.Join(w.dir, filepath.FromSlash(name)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", content.ErrNotFound, desc.Digest)
+	}
+	if err != nil {
+		return err
+	}
+	if fi.Size() != desc.Size {
+		return fmt.Errorf("blob %s has size %d, not %d", desc.Digest, fi.Size(), desc.Size)
+	}
+	return nil
+}
+
+// checkRefName validates the AnnotationRefName of desc, if any.
+func checkRefName(desc v1.Descriptor) error {
+	if name, ok := desc.Annotations[v1.AnnotationRefName]; ok {
+		return ValidateRefName(name)
+	}
+	return nil
+}
+
+// removeRef returns the descriptors of descs without those whose
+// AnnotationRefName is refName.
+func removeRef(descs []v1.Descriptor, refName string) []v1.Descriptor {
+	var kept []v1.Descriptor
+	for _, desc := range descs {
+		if name, ok := desc.Annotations[v1.AnnotationRefName]; !ok || name != refName {
+			kept = append(kept, desc)
+		}
+	}
+	return kept
+}
+
+// withAnnotation returns a copy of annotations with key set to value.
+func withAnnotation(annotations map[string]string, key, value string) map[string]string {
+	copied := make(map[string]string, len(annotations)+1)
+	for k, v := range annotations {
+		copied[k] = v
+	}
+	copied[key] = value
+	return copied
+}
+
+// containsDescriptor returns whether descs has a descriptor identical to desc.
+func containsDescriptor(descs []v1.Descriptor, desc v1.Descriptor) bool {
+	raw, err := json.Marshal(desc)
+	if err != nil {
+		return false
+	}
+	for _, d := range descs {
+		if other, err := json.Marshal(d); err == nil && string(other) == string(raw) {
+			return true
+		}
+	}
+	return false
+}
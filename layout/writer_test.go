@@ -0,0 +1,222 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/content"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func pushBlob(t *testing.T, w *Writer, mediaType string, blob []byte) v1.Descriptor {
+	t.Helper()
+	desc := v1.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(blob), Size: int64(len(blob))}
+	if err := w.Push(context.Background(), desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal(err)
+	}
+	return desc
+}
+
+// checkNoTempFiles fails if a temporary file of a Writer was left in dir.
+func checkNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	err := filepath.Walk(dir, func(path string, _ os.FileInfo, err error) error {
+		if err == nil && strings.HasPrefix(filepath.Base(path), tempPrefix) {
+			t.Errorf("temporary file left: %s", path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "layout")
+	w, err := Create(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{v1.ImageLayoutFile, v1.ImageIndexFile} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	first := pushBlob(t, w, v1.MediaTypeImageManifest, []byte(`{"first":1}`))
+	second := pushBlob(t, w, v1.MediaTypeImageManifest, []byte(`{"second":2}`))
+	// pushing existing content is a no-op
+	pushBlob(t, w, v1.MediaTypeImageManifest, []byte(`{"first":1}`))
+
+	if err := w.Tag(ctx, first, "latest"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Tag(ctx, first, "v1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Tag(ctx, second, "latest"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Add(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Add(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Tag(ctx, v1.Descriptor{Digest: digest.FromString("missing"), Size: 7}, "missing"); !errors.Is(err, content.ErrNotFound) {
+		t.Errorf("unexpected error tagging a missing blob: %v", err)
+	}
+	for _, reference := range []string{"", "has space", "../latest", "a//b"} {
+		if err := w.Tag(ctx, first, reference); !errors.Is(err, ErrInvalidReference) {
+			t.Errorf("unexpected error tagging with %q: %v", reference, err)
+		}
+	}
+	invalid := second
+	invalid.Annotations = map[string]string{v1.AnnotationRefName: "has space"}
+	if err := w.Add(ctx, invalid); !errors.Is(err, ErrInvalidReference) {
+		t.Errorf("unexpected error adding an invalid reference: %v", err)
+	}
+
+	// the layout is readable, by a new Reader
+	r, err := Open(os.DirFS(dir), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := r.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var refs []string
+	for _, desc := range index.Manifests {
+		refs = append(refs, desc.Annotations[v1.AnnotationRefName]+"="+desc.Digest.Encoded()[:4])
+	}
+	expected := []string{"v1=" + first.Digest.Encoded()[:4], "latest=" + second.Digest.Encoded()[:4], "=" + second.Digest.Encoded()[:4]}
+	if fmt.Sprint(refs) != fmt.Sprint(expected) {
+		t.Errorf("unexpected index entries %v, expected %v", refs, expected)
+	}
+	if blob, err := content.ReadAll(ctx, r, second, 1024); err != nil || string(blob) != `{"second":2}` {
+		t.Errorf("unexpected blob %s: %v", blob, err)
+	}
+
+	if err := w.Untag(ctx, "latest"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Untag(ctx, "latest"); !errors.Is(err, content.ErrNotFound) {
+		t.Errorf("unexpected error removing a missing tag: %v", err)
+	}
+	if _, err := w.Resolve(ctx, "latest"); !errors.Is(err, content.ErrNotFound) {
+		t.Errorf("unexpected error resolving a removed tag: %v", err)
+	}
+
+	// reopening keeps the index
+	w, err = Create(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc, err := w.Resolve(ctx, "v1"); err != nil || desc.Digest != first.Digest {
+		t.Errorf("unexpected v1 after reopening: %v, %v", desc, err)
+	}
+	checkNoTempFiles(t, dir)
+}
+
+func TestWriterPushInvalid(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	w, err := Create(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := []byte("content")
+	desc := v1.Descriptor{Digest: digest.FromBytes(blob), Size: int64(len(blob))}
+	for _, tt := range []struct {
+		name string
+		desc v1.Descriptor
+		blob string
+	}{
+		{name: "corrupt", desc: desc, blob: "CONTENT"},
+		{name: "truncated", desc: desc, blob: "conten"},
+		{name: "too long", desc: desc, blob: "content!"},
+		{name: "malformed digest", desc: v1.Descriptor{Digest: "sha256:../../x", Size: 7}, blob: "content"},
+		{name: "unknown algorithm", desc: v1.Descriptor{Digest: "unknown:0123456789abcdef", Size: 7}, blob: "content"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := w.Push(ctx, tt.desc, strings.NewReader(tt.blob)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+	if _, err := w.Fetch(ctx, desc); !errors.Is(err, content.ErrNotFound) {
+		t.Errorf("invalid content was stored: %v", err)
+	}
+	checkNoTempFiles(t, dir)
+}
+
+func TestWriterConcurrent(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	w, err := Create(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// each writer has its own lock file descriptor, as would
+			// separate processes
+			w, err := Create(dir, Options{})
+			if err != nil {
+				errs <- err
+				return
+			}
+			blob := []byte(fmt.Sprintf(`{"n":%d}`, i))
+			desc := v1.Descriptor{MediaType: v1.MediaTypeImageManifest, Digest: digest.FromBytes(blob), Size: int64(len(blob))}
+			if err := w.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+				errs <- err
+				return
+			}
+			errs <- w.Tag(ctx, desc, fmt.Sprintf("tag%d", i))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	index, err := w.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Manifests) != n {
+		t.Errorf("expected %d entries, got %d", n, len(index.Manifests))
+	}
+	checkNoTempFiles(t, dir)
+}
@@ -0,0 +1,28 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package platform matches the platforms of image manifests, as listed in
+// the descriptors of an index.
+package platform
+
+import v1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+// Match returns whether the platform of a manifest, have, matches the wanted
+// platform want. The OS and architecture must be equal, and the variant and
+// OS version must be equal if want has them.
+func Match(want, have v1.Platform) bool {
+	return want.OS == have.OS && want.Architecture == have.Architecture &&
+		(want.Variant == "" || want.Variant == have.Variant) &&
+		(want.OSVersion == "" || want.OSVersion == have.OSVersion)
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestMatch(t *testing.T) {
+	arm64 := v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}
+	for _, tt := range []struct {
+		want, have v1.Platform
+		match      bool
+	}{
+		{want: v1.Platform{OS: "linux", Architecture: "arm64"}, have: arm64, match: true},
+		{want: arm64, have: arm64, match: true},
+		{want: v1.Platform{OS: "linux", Architecture: "amd64"}, have: arm64},
+		{want: v1.Platform{OS: "windows", Architecture: "arm64"}, have: arm64},
+		{want: v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v9"}, have: arm64},
+		{want: v1.Platform{OS: "linux", Architecture: "arm64", OSVersion: "6.1"}, have: arm64},
+		{want: arm64, have: v1.Platform{OS: "linux", Architecture: "arm64"}},
+	} {
+		if match := Match(tt.want, tt.have); match != tt.match {
+			t.Errorf("Match(%+v, %+v) = %v, want %v", tt.want, tt.have, match, tt.match)
+		}
+	}
+}
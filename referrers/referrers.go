@@ -0,0 +1,122 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package referrers lists the referrers of a manifest, which are the
+// manifests and indexes whose subject field points to it, in the form of the
+// image index returned by the referrers API of the OCI distribution
+// specification.
+package referrers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/content"
+	"github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// maxManifestSize is the largest manifest or index fetched.
+const maxManifestSize = 4 << 20
+
+// Descriptor fetches the manifest or index described by desc and returns the
+// descriptor it has as a referrer, along with its subject, which is nil if
+// it has none.
+//
+// The referrer descriptor has the media type, digest and size of desc, the
+// annotations of the manifest, and its artifact type, which is the
+// artifactType field of the manifest or else the media type of its config.
+func Descriptor(ctx context.Context, f content.Fetcher, desc v1.Descriptor) (v1.Descriptor, *v1.Descriptor, error) {
+	if desc.MediaType != v1.MediaTypeImageManifest && desc.MediaType != v1.MediaTypeImageIndex {
+		return v1.Descriptor{}, nil, fmt.Errorf("%s: unsupported media type %q", desc.Digest, desc.MediaType)
+	}
+	raw, err := content.ReadAll(ctx, f, desc, maxManifestSize)
+	if err != nil {
+		return v1.Descriptor{}, nil, err
+	}
+	// the fields shared by manifests and indexes, and the manifest config
+	var m struct {
+		ArtifactType string            `json:"artifactType"`
+		Config       *v1.Descriptor    `json:"config"`
+		Subject      *v1.Descriptor    `json:"subject"`
+		Annotations  map[string]string `json:"annotations"`
+	}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return v1.Descriptor{}, nil, fmt.Errorf("parsing %s: %w", desc.Digest, err)
+	}
+
+	ref := v1.Descriptor{
+		MediaType:    desc.MediaType,
+		Digest:       desc.Digest,
+		Size:         desc.Size,
+		ArtifactType: m.ArtifactType,
+		Annotations:  m.Annotations,
+	}
+	if ref.ArtifactType == "" && desc.MediaType == v1.MediaTypeImageManifest && m.Config != nil {
+		ref.ArtifactType = m.Config.MediaType
+	}
+	return ref, m.Subject, nil
+}
+
+// Index returns the referrers index of subject among the manifests and
+// indexes described by candidates, which are typically every manifest of a
+// store. Candidates of other media types are ignored. The referrers are
+// listed in the order of candidates, once each.
+func Index(ctx context.Context, f content.Fetcher, subject digest.Digest, candidates []v1.Descriptor) (v1.Index, error) {
+	index := newIndex()
+	seen := map[digest.Digest]bool{}
+	for _, desc := range candidates {
+		if desc.MediaType != v1.MediaTypeImageManifest && desc.MediaType != v1.MediaTypeImageIndex {
+			continue
+		}
+		if seen[desc.Digest] {
+			continue
+		}
+		seen[desc.Digest] = true
+
+		ref, s, err := Descriptor(ctx, f, desc)
+		if err != nil {
+			return v1.Index{}, err
+		}
+		if s != nil && s.Digest == subject {
+			index.Manifests = append(index.Manifests, ref)
+		}
+	}
+	return index, nil
+}
+
+// Filter returns the referrers of index that have one of the given artifact
+// types.
+func Filter(index v1.Index, artifactTypes ...string) v1.Index {
+	filtered := newIndex()
+	for _, desc := range index.Manifests {
+		for _, t := range artifactTypes {
+			if desc.ArtifactType == t {
+				filtered.Manifests = append(filtered.Manifests, desc)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+func newIndex() v1.Index {
+	return v1.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: v1.MediaTypeImageIndex,
+		Manifests: []v1.Descriptor{},
+	}
+}
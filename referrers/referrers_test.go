@@ -0,0 +1,110 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package referrers
+
+import (
+	"bytes"
+	"context"
+	_ "crypto/sha256" // required to install sha256 digest support
+	"reflect"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/builder"
+	"github.com/opencontainers/image-spec/content"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// add builds a manifest with m, pushes it to s and returns its descriptor.
+func add(t *testing.T, s *content.Memory, m *builder.Manifest) v1.Descriptor {
+	t.Helper()
+	res, err := m.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Push(context.Background(), res.Descriptor, bytes.NewReader(res.Bytes)); err != nil {
+		t.Fatal(err)
+	}
+	return res.Descriptor
+}
+
+type testGraph struct {
+	store                             *content.Memory
+	image, sbom, signature, unrelated v1.Descriptor
+	config                            v1.Descriptor
+}
+
+func newTestGraph(t *testing.T) testGraph {
+	s := content.NewMemory()
+	image := add(t, s, builder.NewManifest().Validate(builder.NoValidation).Config(v1.MediaTypeImageConfig, []byte(`{}`)))
+	return testGraph{
+		store: s,
+		image: image,
+		sbom: add(t, s, builder.NewManifest().Validate(builder.NoValidation).
+			ArtifactType("application/spdx+json").
+			Subject(image).
+			Annotation(v1.AnnotationCreated, "2026-01-02T03:04:05Z")),
+		signature: add(t, s, builder.NewManifest().Validate(builder.NoValidation).
+			Config("application/vnd.example.signature.config+json", []byte(`{}`)).
+			Subject(image)),
+		unrelated: add(t, s, builder.NewManifest().Validate(builder.NoValidation).
+			ArtifactType("application/spdx+json").
+			Subject(v1.DescriptorEmptyJSON)),
+		config: v1.Descriptor{MediaType: v1.MediaTypeImageConfig, Digest: digest.FromString("{}"), Size: 2},
+	}
+}
+
+func TestIndex(t *testing.T) {
+	g := newTestGraph(t)
+	index, err := Index(context.Background(), g.store, g.image.Digest, []v1.Descriptor{
+		g.image, g.sbom, g.config, g.unrelated, g.signature, g.sbom,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.SchemaVersion != 2 || index.MediaType != v1.MediaTypeImageIndex {
+		t.Errorf("unexpected index header: %+v", index)
+	}
+
+	sbom, signature := g.sbom, g.signature
+	sbom.ArtifactType = "application/spdx+json"
+	sbom.Annotations = map[string]string{v1.AnnotationCreated: "2026-01-02T03:04:05Z"}
+	signature.ArtifactType = "application/vnd.example.signature.config+json"
+	if expected := []v1.Descriptor{sbom, signature}; !reflect.DeepEqual(index.Manifests, expected) {
+		t.Errorf("unexpected referrers:\n%+v\nexpected:\n%+v", index.Manifests, expected)
+	}
+
+	filtered := Filter(index, "application/spdx+json")
+	if expected := []v1.Descriptor{sbom}; !reflect.DeepEqual(filtered.Manifests, expected) {
+		t.Errorf("unexpected filtered referrers: %+v", filtered.Manifests)
+	}
+	if filtered := Filter(index, "application/unknown"); filtered.Manifests == nil || len(filtered.Manifests) != 0 {
+		t.Errorf("unexpected filtered referrers: %+v", filtered.Manifests)
+	}
+
+	// referrers of a subject without referrers
+	index, err = Index(context.Background(), g.store, g.sbom.Digest, []v1.Descriptor{g.image, g.sbom})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.Manifests == nil || len(index.Manifests) != 0 {
+		t.Errorf("unexpected referrers: %+v", index.Manifests)
+	}
+
+	// missing candidate
+	if _, err := Index(context.Background(), content.NewMemory(), g.image.Digest, []v1.Descriptor{g.sbom}); err == nil {
+		t.Error("expected missing candidate to fail")
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package referrers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/content"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// TagStore is a store in which the referrers of a subject are maintained as
+// an index pointed to by the tag of the subject, for stores without
+// referrers API.
+type TagStore interface {
+	content.Fetcher
+	content.Pusher
+	content.Resolver
+	content.Tagger
+}
+
+// Tag returns the tag of the referrers index of subject in the tag schema of
+// the distribution specification: the algorithm and encoded part of the
+// digest, truncated to 32 and 64 characters, joined by `-`, such as
+// `sha256-<hex>`. Characters not allowed in tags are replaced by `-`.
+func Tag(subject digest.Digest) string {
+	return tagComponent(string(subject.Algorithm()), 32) + "-" + tagComponent(subject.Encoded(), 64)
+}
+
+func tagComponent(s string, limit int) string {
+	if len(s) > limit {
+		s = s[:limit]
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		}
+		return '-'
+	}, s)
+}
+
+// FetchTagged returns the referrers index of subject pointed to by its tag,
+// or an empty index if the tag does not exist.
+func FetchTagged(ctx context.Context, s interface {
+	content.Fetcher
+	content.Resolver
+}, subject digest.Digest) (v1.Index, error) {
+	desc, err := s.Resolve(ctx, Tag(subject))
+	if errors.Is(err, content.ErrNotFound) {
+		return newIndex(), nil
+	}
+	if err != nil {
+		return v1.Index{}, err
+	}
+	if desc.MediaType != v1.MediaTypeImageIndex {
+		return v1.Index{}, fmt.Errorf("tag %s points to unexpected media type %q", Tag(subject), desc.MediaType)
+	}
+	raw, err := content.ReadAll(ctx, s, desc, maxManifestSize)
+	if err != nil {
+		return v1.Index{}, err
+	}
+	var index v1.Index
+	if err := json.Unmarshal(raw, &index); err != nil {
+		return v1.Index{}, fmt.Errorf("parsing referrers index %s: %w", desc.Digest, err)
+	}
+	if index.Manifests == nil {
+		index.Manifests = []v1.Descriptor{}
+	}
+	return index, nil
+}
+
+// AddTagged adds the manifest or index described by referrer, which must be
+// in s, to the referrers index of its subject pointed to by the tag of the
+// subject. It returns the descriptor of the updated index.
+func AddTagged(ctx context.Context, s TagStore, referrer v1.Descriptor) (v1.Descriptor, error) {
+	ref, subject, err := Descriptor(ctx, s, referrer)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	if subject == nil {
+		return v1.Descriptor{}, fmt.Errorf("%s has no subject", referrer.Digest)
+	}
+	return updateTagged(ctx, s, subject.Digest, func(manifests []v1.Descriptor) []v1.Descriptor {
+		for i, desc := range manifests {
+			if desc.Digest == ref.Digest {
+				manifests[i] = ref
+				return manifests
+			}
+		}
+		return append(manifests, ref)
+	})
+}
+
+// RemoveTagged removes referrer from the referrers index of subject pointed
+// to by the tag of subject. It returns the descriptor of the updated index.
+func RemoveTagged(ctx context.Context, s TagStore, subject, referrer digest.Digest) (v1.Descriptor, error) {
+	return updateTagged(ctx, s, subject, func(manifests []v1.Descriptor) []v1.Descriptor {
+		kept := manifests[:0]
+		for _, desc := range manifests {
+			if desc.Digest != referrer {
+				kept = append(kept, desc)
+			}
+		}
+		return kept
+	})
+}
+
+func updateTagged(ctx context.Context, s TagStore, subject digest.Digest, update func([]v1.Descriptor) []v1.Descriptor) (v1.Descriptor, error) {
+	index, err := FetchTagged(ctx, s, subject)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	index.Manifests = update(index.Manifests)
+
+	raw, err := json.Marshal(index)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	desc := v1.Descriptor{
+		MediaType: v1.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(raw),
+		Size:      int64(len(raw)),
+	}
+	if err := s.Push(ctx, desc, bytes.NewReader(raw)); err != nil {
+		return v1.Descriptor{}, err
+	}
+	if err := s.Tag(ctx, desc, Tag(subject)); err != nil {
+		return v1.Descriptor{}, err
+	}
+	return desc, nil
+}
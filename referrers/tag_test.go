@@ -0,0 +1,103 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package referrers
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestTag(t *testing.T) {
+	for _, tt := range []struct {
+		subject  digest.Digest
+		expected string
+	}{
+		{
+			subject:  "sha256:6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b",
+			expected: "sha256-6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b",
+		},
+		{
+			subject:  digest.Digest("sha512:" + strings.Repeat("ab", 64)),
+			expected: "sha512-" + strings.Repeat("ab", 32),
+		},
+		{
+			subject:  digest.Digest("multihash+base58:QmRZxt2b1FVZPNqd8hsiykDL3TdBDeTSPX9Kv46HmX4Gx8"),
+			expected: "multihash-base58-QmRZxt2b1FVZPNqd8hsiykDL3TdBDeTSPX9Kv46HmX4Gx8",
+		},
+	} {
+		if tag := Tag(tt.subject); tag != tt.expected {
+			t.Errorf("unexpected tag for %s: %s", tt.subject, tag)
+		}
+	}
+}
+
+func TestTagged(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGraph(t)
+
+	index, err := FetchTagged(ctx, g.store, g.image.Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.Manifests == nil || len(index.Manifests) != 0 {
+		t.Errorf("unexpected referrers: %+v", index.Manifests)
+	}
+
+	for _, tt := range []struct {
+		add      bool
+		referrer v1.Descriptor
+		expected []digest.Digest
+	}{
+		{add: true, referrer: g.sbom, expected: []digest.Digest{g.sbom.Digest}},
+		{add: true, referrer: g.signature, expected: []digest.Digest{g.sbom.Digest, g.signature.Digest}},
+		{add: true, referrer: g.sbom, expected: []digest.Digest{g.sbom.Digest, g.signature.Digest}},
+		{add: false, referrer: g.sbom, expected: []digest.Digest{g.signature.Digest}},
+		{add: false, referrer: g.signature, expected: []digest.Digest{}},
+	} {
+		var err error
+		if tt.add {
+			_, err = AddTagged(ctx, g.store, tt.referrer)
+		} else {
+			_, err = RemoveTagged(ctx, g.store, g.image.Digest, tt.referrer.Digest)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		index, err := FetchTagged(ctx, g.store, g.image.Digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []digest.Digest{}
+		for _, d := range index.Manifests {
+			got = append(got, d.Digest)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Fatalf("unexpected referrers: %v, expected %v", got, tt.expected)
+		}
+	}
+	if _, err := g.store.Resolve(ctx, "sha256-"+g.image.Digest.Encoded()); err != nil {
+		t.Errorf("referrers index was not tagged: %v", err)
+	}
+
+	if _, err := AddTagged(ctx, g.store, g.image); err == nil {
+		t.Error("expected adding a manifest without subject to fail")
+	}
+}
@@ -3,10 +3,16 @@ module github.com/opencontainers/image-spec/schema
 go 1.21
 
 require (
-	github.com/opencontainers/go-digest v1.0.0
+	github.com/opencontainers/go-digest v1.0.1-0.20231025023718-d50d2fec9c98
+	github.com/opencontainers/go-digest/blake3 v0.0.0-20231025023718-d50d2fec9c98
 	github.com/opencontainers/image-spec v1.1.2-0.20250717171153-ab80ff15c2dd
 	github.com/russross/blackfriday/v2 v2.1.0
 	github.com/santhosh-tekuri/jsonschema/v6 v6.0.2
 )
 
-require golang.org/x/text v0.14.0 // indirect
+require (
+	github.com/klauspost/cpuid/v2 v2.2.5 // indirect
+	github.com/zeebo/blake3 v0.2.3 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+)
@@ -0,0 +1,89 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	_ "github.com/opencontainers/go-digest/blake3" // side-effect to install impls, blake3
+	"github.com/opencontainers/image-spec/schema"
+)
+
+func TestValidateWithPolicy(t *testing.T) {
+	reject := func(_ digest.Digest, err error) error { return err }
+
+	const unknown = "unknown:0123456789abcdef0123456789abcdef"
+	blake3 := digest.BLAKE3.FromString("content")
+
+	for _, tt := range []struct {
+		name      string
+		validator schema.Validator
+		document  string
+	}{
+		{
+			name:      "descriptor",
+			validator: schema.ValidatorMediaTypeDescriptor,
+			document:  `{"mediaType":"application/octet-stream","size":7,"digest":"%s"}`,
+		},
+		{
+			name:      "manifest layer",
+			validator: schema.ValidatorMediaTypeManifest,
+			document: `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json",` +
+				`"config":{"mediaType":"application/vnd.oci.image.config.v1+json","size":2,"digest":"sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"},` +
+				`"layers":[{"mediaType":"application/vnd.oci.image.layer.v1.tar","size":7,"digest":"%s"}]}`,
+		},
+		{
+			name:      "index subject",
+			validator: schema.ValidatorMediaTypeImageIndex,
+			document: `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[],` +
+				`"subject":{"mediaType":"application/vnd.oci.image.manifest.v1+json","size":7,"digest":"%s"}}`,
+		},
+		{
+			name:      "config diff_id",
+			validator: schema.ValidatorMediaTypeImageConfig,
+			document:  `{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":["%s"]}}`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			withBLAKE3 := strings.Replace(tt.document, "%s", blake3.String(), 1)
+			withUnknown := strings.Replace(tt.document, "%s", unknown, 1)
+
+			if err := tt.validator.ValidateWithPolicy(strings.NewReader(withBLAKE3), reject); err != nil {
+				t.Errorf("BLAKE3 rejected: %v", err)
+			}
+			if err := tt.validator.Validate(strings.NewReader(withUnknown)); err != nil {
+				t.Errorf("unknown algorithm rejected by default: %v", err)
+			}
+			if err := tt.validator.ValidateWithPolicy(strings.NewReader(withUnknown), reject); !errors.Is(err, digest.ErrDigestUnsupported) {
+				t.Errorf("unexpected error with the reject policy: %v", err)
+			}
+
+			var warnings []error
+			warn := func(_ digest.Digest, err error) error {
+				warnings = append(warnings, err)
+				return nil
+			}
+			if err := tt.validator.ValidateWithPolicy(strings.NewReader(withUnknown), warn); err != nil {
+				t.Errorf("unknown algorithm rejected with the warn policy: %v", err)
+			}
+			if len(warnings) != 1 {
+				t.Errorf("unexpected warnings: %v", warnings)
+			}
+		})
+	}
+}
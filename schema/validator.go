@@ -45,7 +45,21 @@ func (e ValidationError) Error() string {
 }
 
 // Validate validates the given reader against the schema of the wrapped media type.
+// Digests with an unknown algorithm are accepted.
 func (v Validator) Validate(src io.Reader) error {
+	return v.ValidateWithPolicy(src, acceptUnknownAlgorithms)
+}
+
+// ValidateWithPolicy validates the given reader against the schema of the wrapped
+// media type, applying policy to the digests with an unknown algorithm, such as
+// those of descriptors or diff_ids. The policy is called with the digest and the
+// error of its validation, and returns the error to fail with, if any, as the
+// policies of the identity package do. A nil policy rejects unknown algorithms.
+func (v Validator) ValidateWithPolicy(src io.Reader, policy func(d digest.Digest, err error) error) error {
+	if policy == nil {
+		policy = rejectUnknownAlgorithms
+	}
+
 	// run the media type specific validation
 	if fn, ok := validateByMediaType[v]; ok {
 		if fn == nil {
@@ -57,7 +71,7 @@ func (v Validator) Validate(src io.Reader) error {
 			return fmt.Errorf("failed to read input: %w", err)
 		}
 		src = bytes.NewReader(buf)
-		err = fn(buf)
+		err = fn(buf, policy)
 		if err != nil {
 			return err
 		}
@@ -126,7 +140,9 @@ func (v Validator) validateSchema(src io.Reader) error {
 	return nil
 }
 
-type validateFunc func([]byte) error
+type validateFunc func([]byte, algorithmPolicy) error
+
+type algorithmPolicy = func(d digest.Digest, err error) error
 
 var validateByMediaType = map[Validator]validateFunc{
 	ValidatorMediaTypeImageConfig: validateConfig,
@@ -135,7 +151,7 @@ var validateByMediaType = map[Validator]validateFunc{
 	ValidatorMediaTypeManifest:    validateManifest,
 }
 
-func validateManifest(buf []byte) error {
+func validateManifest(buf []byte, policy algorithmPolicy) error {
 	header := v1.Manifest{}
 
 	err := json.Unmarshal(buf, &header)
@@ -143,10 +159,20 @@ func validateManifest(buf []byte) error {
 		return fmt.Errorf("manifest format mismatch: %w", err)
 	}
 
+	descs := append([]v1.Descriptor{header.Config}, header.Layers...)
+	if header.Subject != nil {
+		descs = append(descs, *header.Subject)
+	}
+	for _, desc := range descs {
+		if err := checkAlgorithm(desc.Digest, policy); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func validateDescriptor(buf []byte) error {
+func validateDescriptor(buf []byte, policy algorithmPolicy) error {
 	header := v1.Descriptor{}
 
 	err := json.Unmarshal(buf, &header)
@@ -156,13 +182,12 @@ func validateDescriptor(buf []byte) error {
 
 	err = header.Digest.Validate()
 	if errors.Is(err, digest.ErrDigestUnsupported) {
-		// we ignore unsupported algorithms
-		return nil
+		return policy(header.Digest, err)
 	}
 	return err
 }
 
-func validateIndex(buf []byte) error {
+func validateIndex(buf []byte, policy algorithmPolicy) error {
 	header := v1.Index{}
 
 	err := json.Unmarshal(buf, &header)
@@ -170,10 +195,20 @@ func validateIndex(buf []byte) error {
 		return fmt.Errorf("index format mismatch: %w", err)
 	}
 
+	descs := header.Manifests
+	if header.Subject != nil {
+		descs = append(descs, *header.Subject)
+	}
+	for _, desc := range descs {
+		if err := checkAlgorithm(desc.Digest, policy); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func validateConfig(buf []byte) error {
+func validateConfig(buf []byte, policy algorithmPolicy) error {
 	header := v1.Image{}
 
 	err := json.Unmarshal(buf, &header)
@@ -181,6 +216,12 @@ func validateConfig(buf []byte) error {
 		return fmt.Errorf("config format mismatch: %w", err)
 	}
 
+	for _, diffID := range header.RootFS.DiffIDs {
+		if err := checkAlgorithm(diffID, policy); err != nil {
+			return err
+		}
+	}
+
 	envRegexp := regexp.MustCompile(`^[^=]+=.*$`)
 	for _, e := range header.Config.Env {
 		if !envRegexp.MatchString(e) {
@@ -190,3 +231,16 @@ func validateConfig(buf []byte) error {
 
 	return nil
 }
+
+func acceptUnknownAlgorithms(digest.Digest, error) error { return nil }
+
+func rejectUnknownAlgorithms(_ digest.Digest, err error) error { return err }
+
+// checkAlgorithm applies policy to d if its algorithm is unknown. Malformed
+// digests are left to the schema validation.
+func checkAlgorithm(d digest.Digest, policy algorithmPolicy) error {
+	if err := d.Validate(); errors.Is(err, digest.ErrDigestUnsupported) {
+		return policy(d, err)
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spdx parses SPDX license expressions, as used by the
+// `org.opencontainers.image.licenses` annotation.
+//
+// The grammar follows Annex D of the SPDX 2.3 specification:
+// https://spdx.github.io/spdx-spec/v2.3/SPDX-license-expressions/
+package spdx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expression is a parsed SPDX license expression. It is either a *License or
+// a *Compound.
+type Expression interface {
+	// String returns the expression in its canonical textual form.
+	String() string
+
+	isExpression()
+}
+
+// License is a simple expression: a license identifier or reference,
+// optionally followed by "+" and a "WITH" exception.
+type License struct {
+	// ID is the short form license identifier, e.g. `Apache-2.0`, or a
+	// license reference such as `LicenseRef-custom`.
+	ID string
+
+	// DocumentRef is the optional `DocumentRef-` prefix of a license
+	// reference, without the trailing colon.
+	DocumentRef string
+
+	// OrLater is set when the identifier is followed by "+".
+	OrLater bool
+
+	// Exception is the license exception identifier following "WITH", if any.
+	Exception string
+}
+
+// IsRef reports whether the license is a `LicenseRef-` reference rather than
+// a license list identifier.
+func (l *License) IsRef() bool {
+	return strings.HasPrefix(l.ID, licenseRefPrefix)
+}
+
+func (l *License) String() string {
+	var b strings.Builder
+	if l.DocumentRef != "" {
+		b.WriteString(l.DocumentRef)
+		b.WriteByte(':')
+	}
+	b.WriteString(l.ID)
+	if l.OrLater {
+		b.WriteByte('+')
+	}
+	if l.Exception != "" {
+		b.WriteString(" WITH ")
+		b.WriteString(l.Exception)
+	}
+	return b.String()
+}
+
+func (*License) isExpression() {}
+
+// Operator is a conjunctive or disjunctive operator joining two expressions.
+type Operator string
+
+const (
+	// And requires compliance with both sides of the expression.
+	And Operator = "AND"
+
+	// Or allows a choice between both sides of the expression.
+	Or Operator = "OR"
+)
+
+// Compound joins two expressions with an operator.
+type Compound struct {
+	Op          Operator
+	Left, Right Expression
+}
+
+func (c *Compound) String() string {
+	return c.operand(c.Left) + " " + string(c.Op) + " " + c.operand(c.Right)
+}
+
+// operand renders a child expression, adding parentheses where the child
+// binds less tightly than c.
+func (c *Compound) operand(e Expression) string {
+	if sub, ok := e.(*Compound); ok && c.Op == And && sub.Op == Or {
+		return "(" + sub.String() + ")"
+	}
+	return e.String()
+}
+
+func (*Compound) isExpression() {}
+
+const (
+	licenseRefPrefix  = "LicenseRef-"
+	documentRefPrefix = "DocumentRef-"
+)
+
+// Parse parses s as an SPDX license expression. Only the syntax is checked;
+// identifiers are not compared against the SPDX license list.
+func Parse(s string) (Expression, error) {
+	p := parser{input: s}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("invalid license expression %q: empty", s)
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return e, nil
+}
+
+type token struct {
+	text   string
+	offset int
+}
+
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	offset := len(p.input)
+	if p.pos < len(p.tokens) {
+		offset = p.tokens[p.pos].offset
+	}
+	return fmt.Errorf("invalid license expression %q at offset %d: %s", p.input, offset, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) tokenize() error {
+	s := p.input
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == '+':
+			p.tokens = append(p.tokens, token{text: s[i : i+1], offset: i})
+			i++
+		case isIDChar(c) || c == ':':
+			start := i
+			for i < len(s) && (isIDChar(s[i]) || s[i] == ':') {
+				i++
+			}
+			p.tokens = append(p.tokens, token{text: s[start:i], offset: start})
+		default:
+			return fmt.Errorf("invalid license expression %q at offset %d: unexpected character %q", s, i, c)
+		}
+	}
+	return nil
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos].text
+	}
+	return ""
+}
+
+func (p *parser) parseOr() (Expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == string(Or) {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Compound{Op: Or, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expression, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == string(And) {
+		p.pos++
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Compound{Op: And, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parsePrimary() (Expression, error) {
+	switch tok := p.peek(); tok {
+	case "":
+		return nil, p.errorf("unexpected end of expression")
+	case "(":
+		p.pos++
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, p.errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return e, nil
+	case ")", "+", string(And), string(Or), "WITH":
+		return nil, p.errorf("unexpected %q", tok)
+	}
+	return p.parseSimple()
+}
+
+func (p *parser) parseSimple() (Expression, error) {
+	l := &License{}
+	id := p.peek()
+	if strings.HasPrefix(id, documentRefPrefix) {
+		i := strings.IndexByte(id, ':')
+		if i < 0 {
+			return nil, p.errorf("document reference %q must be followed by \":LicenseRef-\"", id)
+		}
+		l.DocumentRef, id = id[:i], id[i+1:]
+		if !validIDString(l.DocumentRef[len(documentRefPrefix):]) || !strings.HasPrefix(id, licenseRefPrefix) {
+			return nil, p.errorf("invalid license reference %q", p.peek())
+		}
+	}
+	if strings.HasPrefix(id, licenseRefPrefix) {
+		if !validIDString(id[len(licenseRefPrefix):]) {
+			return nil, p.errorf("invalid license reference %q", id)
+		}
+	} else if !validIDString(id) {
+		return nil, p.errorf("invalid license identifier %q", id)
+	}
+	l.ID = id
+	p.pos++
+
+	if p.peek() == "+" {
+		if l.IsRef() {
+			return nil, p.errorf("\"+\" is not allowed after a license reference")
+		}
+		l.OrLater = true
+		p.pos++
+	}
+	if p.peek() == "WITH" {
+		p.pos++
+		exception := p.peek()
+		if !validIDString(exception) || exception == string(And) || exception == string(Or) || exception == "WITH" {
+			return nil, p.errorf("invalid license exception %q", exception)
+		}
+		l.Exception = exception
+		p.pos++
+	}
+	return l, nil
+}
+
+// isIDChar reports whether c may appear in an SPDX idstring.
+func isIDChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '.'
+}
+
+func validIDString(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isIDChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spdx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected Expression
+		str      string
+		fail     bool
+	}{
+		{
+			input:    "MIT",
+			expected: &License{ID: "MIT"},
+		},
+		{
+			input:    "GPL-2.0+",
+			expected: &License{ID: "GPL-2.0", OrLater: true},
+		},
+		{
+			input:    "GPL-2.0-or-later WITH Classpath-exception-2.0",
+			expected: &License{ID: "GPL-2.0-or-later", Exception: "Classpath-exception-2.0"},
+		},
+		{
+			input:    "DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2",
+			expected: &License{ID: "LicenseRef-MIT-Style-2", DocumentRef: "DocumentRef-spdx-tool-1.2"},
+		},
+		{
+			input: "MIT OR Apache-2.0 AND BSD-3-Clause",
+			expected: &Compound{
+				Op:   Or,
+				Left: &License{ID: "MIT"},
+				Right: &Compound{
+					Op:    And,
+					Left:  &License{ID: "Apache-2.0"},
+					Right: &License{ID: "BSD-3-Clause"},
+				},
+			},
+		},
+		{
+			input: "(MIT OR Apache-2.0) AND BSD-3-Clause",
+			expected: &Compound{
+				Op: And,
+				Left: &Compound{
+					Op:    Or,
+					Left:  &License{ID: "MIT"},
+					Right: &License{ID: "Apache-2.0"},
+				},
+				Right: &License{ID: "BSD-3-Clause"},
+			},
+		},
+		{
+			input: " ( MIT )  AND ( LicenseRef-foo )",
+			str:   "MIT AND LicenseRef-foo",
+			expected: &Compound{
+				Op:    And,
+				Left:  &License{ID: "MIT"},
+				Right: &License{ID: "LicenseRef-foo"},
+			},
+		},
+		{input: "", fail: true},
+		{input: "Apache 2", fail: true},
+		{input: "MIT AND", fail: true},
+		{input: "OR MIT", fail: true},
+		{input: "(MIT", fail: true},
+		{input: "MIT)", fail: true},
+		{input: "MIT WITH", fail: true},
+		{input: "MIT WITH AND", fail: true},
+		{input: "LicenseRef-foo+", fail: true},
+		{input: "LicenseRef-", fail: true},
+		{input: "DocumentRef-foo", fail: true},
+		{input: "DocumentRef-foo:MIT", fail: true},
+		{input: "MIT/X11", fail: true},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			e, err := Parse(tt.input)
+			if tt.fail {
+				if err == nil {
+					t.Fatalf("expected error, got %v", e)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(e, tt.expected) {
+				t.Errorf("unexpected expression: %#v != %#v", e, tt.expected)
+			}
+			str := tt.str
+			if str == "" {
+				str = tt.input
+			}
+			if e.String() != str {
+				t.Errorf("unexpected string form: %q != %q", e.String(), str)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spdx
+
+import (
+	_ "embed" // required for go:embed
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LicenseListVersion is the version of the SPDX License List embedded in this package.
+const LicenseListVersion = "3.25"
+
+var (
+	//go:embed licenses.txt
+	licenseList string
+
+	//go:embed exceptions.txt
+	exceptionList string
+
+	// licenses and exceptions map lower-cased identifiers to their canonical
+	// spelling, as SPDX identifiers are matched case-insensitively.
+	licenses   = loadList(licenseList)
+	exceptions = loadList(exceptionList)
+)
+
+func loadList(list string) map[string]string {
+	ids := map[string]string{}
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids[strings.ToLower(line)] = line
+	}
+	return ids
+}
+
+// LookupLicense reports whether id is on the SPDX License List, and returns
+// its canonical spelling.
+func LookupLicense(id string) (string, bool) {
+	canonical, ok := licenses[strings.ToLower(id)]
+	return canonical, ok
+}
+
+// LookupException reports whether id is a known SPDX license exception, and
+// returns its canonical spelling.
+func LookupException(id string) (string, bool) {
+	canonical, ok := exceptions[strings.ToLower(id)]
+	return canonical, ok
+}
+
+// Validate checks that every license identifier in e is on the SPDX License
+// List and every exception is a known license exception. License references
+// (`LicenseRef-`) are always accepted.
+func Validate(e Expression) error {
+	var err error
+	walk(e, func(l *License) {
+		if err != nil {
+			return
+		}
+		if _, ok := LookupLicense(l.ID); !ok && !l.IsRef() {
+			err = fmt.Errorf("unknown license identifier %q", l.ID)
+			return
+		}
+		if _, ok := LookupException(l.Exception); !ok && l.Exception != "" {
+			err = fmt.Errorf("unknown license exception %q", l.Exception)
+		}
+	})
+	return err
+}
+
+// LicenseIDs returns the sorted set of license identifiers and references
+// used in e. Identifiers on the SPDX License List are returned in their
+// canonical spelling; the "+" operator and exceptions are not included.
+func LicenseIDs(e Expression) []string {
+	set := map[string]struct{}{}
+	walk(e, func(l *License) {
+		id := l.ID
+		if canonical, ok := LookupLicense(id); ok {
+			id = canonical
+		}
+		if l.DocumentRef != "" {
+			id = l.DocumentRef + ":" + id
+		}
+		set[id] = struct{}{}
+	})
+	ids := make([]string, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// walk calls fn for every simple expression in e, from left to right.
+func walk(e Expression, fn func(*License)) {
+	switch e := e.(type) {
+	case *License:
+		fn(e)
+	case *Compound:
+		walk(e.Left, fn)
+		walk(e.Right, fn)
+	}
+}
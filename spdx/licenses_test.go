@@ -0,0 +1,60 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spdx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		fail  bool
+	}{
+		{input: "Apache-2.0"},
+		{input: "apache-2.0 OR mit"},
+		{input: "GPL-2.0-or-later WITH Classpath-exception-2.0"},
+		{input: "LGPL-2.1+ AND LicenseRef-proprietary"},
+		// identifiers added by recent versions of the list
+		{input: "GPL-2.0-or-later WITH GStreamer-exception-2005"},
+		{input: "LGPL-2.1-only WITH LLGPL"},
+		{input: "GPL-2.0-only WITH erlang-otp-linking-exception OR 3D-Slicer-1.0"},
+		{input: "GPL", fail: true},
+		{input: "MIT AND Apache-2", fail: true},
+		{input: "GPL-2.0-only WITH Made-up-exception", fail: true},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			e, err := Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Validate(e); (err != nil) != tt.fail {
+				t.Errorf("expected validation failure %t, got %v", tt.fail, err)
+			}
+		})
+	}
+}
+
+func TestLicenseIDs(t *testing.T) {
+	e, err := Parse("(mit OR Apache-2.0) AND GPL-2.0+ WITH Classpath-exception-2.0 AND MIT AND DocumentRef-x:LicenseRef-y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Apache-2.0", "DocumentRef-x:LicenseRef-y", "GPL-2.0", "MIT"}
+	if ids := LicenseIDs(e); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("unexpected license IDs: %v != %v", ids, expected)
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transfer copies graphs of content, such as images and their
+// referrers, between stores.
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/content"
+	"github.com/opencontainers/image-spec/graph"
+	"github.com/opencontainers/image-spec/layout"
+	"github.com/opencontainers/image-spec/platform"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// DefaultConcurrency is the number of blobs copied concurrently by default.
+const DefaultConcurrency = 4
+
+// Source is the store content is copied from.
+//
+// If the source implements ReferrersLister, the referrers of the copied
+// manifests can be copied as well.
+type Source interface {
+	content.Fetcher
+}
+
+// Destination is the store content is copied to.
+//
+// If the destination implements content.Exister, blobs that exist are not
+// copied, otherwise every blob is pushed.
+type Destination interface {
+	content.Pusher
+	content.Tagger
+}
+
+// ReferrersLister lists the referrers of a manifest in a store.
+type ReferrersLister interface {
+	// Referrers returns the descriptors of the manifests and indexes whose
+	// subject is subject.
+	Referrers(ctx context.Context, subject v1.Descriptor) ([]v1.Descriptor, error)
+}
+
+// Options are the options of Copy.
+type Options struct {
+	// Concurrency is the maximum number of blobs fetched or copied
+	// concurrently, or DefaultConcurrency if zero.
+	Concurrency int
+
+	// Referrers also copies the referrers of the copied manifests and
+	// indexes, and theirs in turn. The source must implement
+	// ReferrersLister.
+	Referrers bool
+
+	// Platforms restricts the manifests of indexes copied to those with one
+	// of these platforms, as matched by platform.Match. Entries without a
+	// platform, such as nested indexes, are always copied. Every manifest is
+	// copied if Platforms is empty.
+	Platforms []v1.Platform
+
+	// Tag is the reference the root is tagged with in the destination, if
+	// not empty. It must be valid as checked by layout.ValidateRefName.
+	Tag string
+}
+
+// Copy copies the graph of content rooted at root from src to dst, walking
+// it with graph.Walk: the children of manifests and indexes are copied
+// before them, such that the destination never has a manifest without its
+// blobs. Every blob is verified as it is copied.
+//
+// With Platforms, indexes are copied as is, while the manifests they list
+// for other platforms are not: the destination then has indexes whose
+// manifests are partly missing, which a layout fsck reports.
+//
+// Referrers are copied once the graph of their subject is, walking each
+// referrer as a root, such that a referrer listing its own subject among
+// its children is not waiting for itself.
+func Copy(ctx context.Context, dst Destination, src Source, root v1.Descriptor, opts Options) error {
+	if opts.Tag != "" {
+		if err := layout.ValidateRefName(opts.Tag); err != nil {
+			return err
+		}
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	var lister ReferrersLister
+	if opts.Referrers {
+		var ok bool
+		if lister, ok = src.(ReferrersLister); !ok {
+			return errors.New("source cannot list referrers")
+		}
+	}
+
+	exister, _ := dst.(content.Exister)
+	c := &copier{
+		dst:     dst,
+		src:     src,
+		exister: exister,
+		opts:    opts,
+		visited: graph.NewSet(),
+		calls:   map[digest.Digest]*call{},
+	}
+	if err := c.walk(ctx, root); err != nil {
+		return err
+	}
+	// the referrers of the copied manifests, which may have referrers too
+	referred := map[digest.Digest]bool{}
+	for lister != nil && len(c.manifests) > 0 {
+		subject := c.manifests[0]
+		c.manifests = c.manifests[1:]
+		if referred[subject.Digest] {
+			continue
+		}
+		referred[subject.Digest] = true
+		referrers, err := lister.Referrers(ctx, subject)
+		if err != nil {
+			return fmt.Errorf("listing referrers of %s: %w", subject.Digest, err)
+		}
+		for _, referrer := range referrers {
+			if err := c.walk(ctx, referrer); err != nil {
+				return err
+			}
+		}
+	}
+	if opts.Tag != "" {
+		return dst.Tag(ctx, root, opts.Tag)
+	}
+	return nil
+}
+
+type copier struct {
+	dst     Destination
+	src     Source
+	exister content.Exister
+	opts    Options
+	visited *graph.Set
+
+	mu        sync.Mutex
+	calls     map[digest.Digest]*call
+	manifests []v1.Descriptor // copied manifests and indexes, for referrers
+}
+
+// call is the copy of a blob, shared by the descriptors of the graph with
+// its digest.
+type call struct {
+	done chan struct{}
+	err  error
+}
+
+// walk copies the graph rooted at root.
+func (c *copier) walk(ctx context.Context, root v1.Descriptor) error {
+	opts := graph.Options{
+		PostOrder:   c.copy,
+		Concurrency: c.opts.Concurrency,
+		Visited:     c.visited,
+	}
+	if len(c.opts.Platforms) > 0 {
+		opts.MediaTypes = map[string]graph.ChildrenFunc{}
+		for mediaType, fn := range graph.ChildrenFuncs {
+			if mediaType == v1.MediaTypeImageIndex || mediaType == "application/vnd.docker.distribution.manifest.list.v2+json" {
+				opts.MediaTypes[mediaType] = c.filterPlatforms(fn)
+			}
+		}
+	}
+	return graph.Walk(ctx, c.src, root, opts)
+}
+
+// filterPlatforms returns a ChildrenFunc returning the manifests of an index
+// found by fn, without those filtered out by platform.
+func (c *copier) filterPlatforms(fn graph.ChildrenFunc) graph.ChildrenFunc {
+	return func(blob []byte) ([]v1.Descriptor, error) {
+		manifests, err := fn(blob)
+		if err != nil {
+			return nil, err
+		}
+		var children []v1.Descriptor
+		for _, desc := range manifests {
+			if desc.Platform == nil || c.matchPlatform(*desc.Platform) {
+				children = append(children, desc)
+			}
+		}
+		return children, nil
+	}
+}
+
+func (c *copier) matchPlatform(have v1.Platform) bool {
+	for _, want := range c.opts.Platforms {
+		if platform.Match(want, have) {
+			return true
+		}
+	}
+	return false
+}
+
+// copy is the post-order handler of the walk, copying the blob of a node
+// once its children are copied. Blobs without children are visited for
+// each reference, and are copied once.
+func (c *copier) copy(ctx context.Context, node graph.Node) error {
+	desc := node.Descriptor
+	c.mu.Lock()
+	cl, ok := c.calls[desc.Digest]
+	if !ok {
+		cl = &call{done: make(chan struct{})}
+		c.calls[desc.Digest] = cl
+		if _, ok := graph.ChildrenFuncs[desc.MediaType]; ok {
+			c.manifests = append(c.manifests, desc)
+		}
+	}
+	c.mu.Unlock()
+	if ok {
+		select {
+		case <-cl.done:
+			return cl.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	cl.err = c.copyBlob(ctx, desc)
+	close(cl.done)
+	return cl.err
+}
+
+// copyBlob copies the blob of desc, streaming it from the source to the
+// destination while verifying it, unless it exists in the destination.
+func (c *copier) copyBlob(ctx context.Context, desc v1.Descriptor) error {
+	if c.exister != nil {
+		exists, err := c.exister.Exists(ctx, desc)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return nil
+		}
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(content.Copy(ctx, pw, c.src, desc))
+	}()
+	err := c.dst.Push(ctx, desc, pr)
+	// unblock the writer if the push stopped reading
+	pr.Close()
+	if err != nil {
+		return fmt.Errorf("copying %s: %w", desc.Digest, err)
+	}
+	return nil
+}
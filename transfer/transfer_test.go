@@ -0,0 +1,304 @@
+// Copyright 2026 The Linux Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/builder"
+	"github.com/opencontainers/image-spec/content"
+	"github.com/opencontainers/image-spec/layout"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// testGraph is a multi-platform image, with a signature referring to its
+// amd64 image, in a layout.
+type testGraph struct {
+	src       *layout.Writer
+	index     v1.Descriptor
+	shared    v1.Descriptor
+	amd64     []v1.Descriptor // manifest, config, layer
+	arm64     []v1.Descriptor
+	signature []v1.Descriptor // manifest, blob
+}
+
+func push(t *testing.T, w *layout.Writer, mediaType string, blob []byte) v1.Descriptor {
+	t.Helper()
+	desc := v1.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(blob), Size: int64(len(blob))}
+	if err := w.Push(context.Background(), desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal(err)
+	}
+	return desc
+}
+
+func pushResult(t *testing.T, w *layout.Writer, res builder.Result, err error) v1.Descriptor {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return push(t, w, res.Descriptor.MediaType, res.Bytes)
+}
+
+func newTestGraph(t *testing.T) testGraph {
+	t.Helper()
+	src, err := layout.Create(t.TempDir(), layout.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := testGraph{src: src, shared: push(t, src, v1.MediaTypeImageLayer, []byte("shared layer"))}
+
+	x := builder.NewIndex().Validate(builder.NoValidation)
+	for _, arch := range []string{"amd64", "arm64"} {
+		config, err := json.Marshal(v1.Image{Platform: v1.Platform{OS: "linux", Architecture: arch}, RootFS: v1.RootFS{Type: "layers"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		configDesc := push(t, src, v1.MediaTypeImageConfig, config)
+		layer := push(t, src, v1.MediaTypeImageLayer, []byte(arch+" layer"))
+		res, err := builder.NewManifest().Validate(builder.NoValidation).ConfigDescriptor(configDesc).LayerDescriptor(g.shared).LayerDescriptor(layer).Build()
+		manifest := pushResult(t, src, res, err)
+		x.Image(res.Bytes, config)
+		if arch == "amd64" {
+			g.amd64 = []v1.Descriptor{manifest, configDesc, layer}
+		} else {
+			g.arm64 = []v1.Descriptor{manifest, configDesc, layer}
+		}
+	}
+	res, err := x.Build()
+	g.index = pushResult(t, src, res, err)
+
+	push(t, src, v1.MediaTypeEmptyJSON, v1.DescriptorEmptyJSON.Data)
+	blob := push(t, src, "application/example.signature", []byte("signature"))
+	res, err = builder.NewManifest().Validate(builder.NoValidation).ArtifactType("application/example.signature").LayerDescriptor(blob).Subject(g.amd64[0]).Build()
+	g.signature = []v1.Descriptor{pushResult(t, src, res, err), blob}
+	return g
+}
+
+// recordingStore records the blobs pushed to a layout, and the number of
+// blobs fetched concurrently from it.
+type recordingStore struct {
+	*layout.Writer
+
+	mu        sync.Mutex
+	pushed    []digest.Digest
+	active    int
+	maxActive int
+}
+
+func (s *recordingStore) Push(ctx context.Context, expected v1.Descriptor, r io.Reader) error {
+	s.mu.Lock()
+	s.pushed = append(s.pushed, expected.Digest)
+	s.mu.Unlock()
+	return s.Writer.Push(ctx, expected, r)
+}
+
+func (s *recordingStore) Fetch(ctx context.Context, desc v1.Descriptor) (io.ReadCloser, error) {
+	rc, err := s.Writer.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active++
+	if s.active > s.maxActive {
+		s.maxActive = s.active
+	}
+	return &activeReader{ReadCloser: rc, s: s}, nil
+}
+
+type activeReader struct {
+	io.ReadCloser
+	s *recordingStore
+}
+
+func (r *activeReader) Close() error {
+	r.s.mu.Lock()
+	r.s.active--
+	r.s.mu.Unlock()
+	return r.ReadCloser.Close()
+}
+
+// corruptFetcher returns corrupt content for the blob with digest corrupt.
+type corruptFetcher struct {
+	content.Fetcher
+	corrupt digest.Digest
+}
+
+func (f corruptFetcher) Fetch(ctx context.Context, desc v1.Descriptor) (io.ReadCloser, error) {
+	if desc.Digest == f.corrupt {
+		return io.NopCloser(strings.NewReader(strings.Repeat("x", int(desc.Size)))), nil
+	}
+	return f.Fetcher.Fetch(ctx, desc)
+}
+
+func newDestination(t *testing.T) *recordingStore {
+	t.Helper()
+	w, err := layout.Create(t.TempDir(), layout.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &recordingStore{Writer: w}
+}
+
+// checkCopied checks whether the blobs of descs are in w.
+func checkCopied(t *testing.T, w *layout.Writer, copied bool, descs ...v1.Descriptor) {
+	t.Helper()
+	for _, desc := range descs {
+		exists, err := w.Exists(context.Background(), desc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists != copied {
+			t.Errorf("blob %s copied: %t, expected %t", desc.Digest, exists, copied)
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGraph(t)
+
+	t.Run("all", func(t *testing.T) {
+		dst := newDestination(t)
+		if err := Copy(ctx, dst, g.src, g.index, Options{Tag: "latest"}); err != nil {
+			t.Fatal(err)
+		}
+		checkCopied(t, dst.Writer, true, append(append([]v1.Descriptor{g.index, g.shared}, g.amd64...), g.arm64...)...)
+		checkCopied(t, dst.Writer, false, g.signature...)
+		if desc, err := dst.Resolve(ctx, "latest"); err != nil || desc.Digest != g.index.Digest {
+			t.Errorf("unexpected tag: %v, %v", desc, err)
+		}
+		// the shared layer is copied once
+		if len(dst.pushed) != 8 {
+			t.Errorf("unexpected blobs pushed: %v", dst.pushed)
+		}
+
+		// blobs that exist are not copied again
+		dst.pushed = nil
+		if err := Copy(ctx, dst, g.src, g.index, Options{}); err != nil {
+			t.Fatal(err)
+		}
+		if len(dst.pushed) != 0 {
+			t.Errorf("existing blobs pushed again: %v", dst.pushed)
+		}
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		dst := content.NewMemory()
+		if err := Copy(ctx, dst, g.src, g.index, Options{Tag: "latest"}); err != nil {
+			t.Fatal(err)
+		}
+		for _, desc := range append([]v1.Descriptor{g.index, g.shared}, g.arm64...) {
+			if exists, err := dst.Exists(ctx, desc); err != nil || !exists {
+				t.Errorf("blob %s was not copied", desc.Digest)
+			}
+		}
+		if tags, err := dst.Tags(ctx); err != nil || len(tags) != 1 || tags[0] != "latest" {
+			t.Errorf("unexpected tags: %v, %v", tags, err)
+		}
+	})
+
+	t.Run("invalid tag", func(t *testing.T) {
+		dst := newDestination(t)
+		if err := Copy(ctx, dst, g.src, g.index, Options{Tag: "has space"}); !errors.Is(err, layout.ErrInvalidReference) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		// nothing is copied before the tag is checked
+		if len(dst.pushed) != 0 {
+			t.Errorf("blobs pushed with an invalid tag: %v", dst.pushed)
+		}
+	})
+
+	t.Run("platform", func(t *testing.T) {
+		dst := newDestination(t)
+		opts := Options{Platforms: []v1.Platform{{OS: "linux", Architecture: "arm64"}}}
+		if err := Copy(ctx, dst, g.src, g.index, opts); err != nil {
+			t.Fatal(err)
+		}
+		// the index is copied as is, listing the amd64 manifest not copied
+		checkCopied(t, dst.Writer, true, append([]v1.Descriptor{g.index, g.shared}, g.arm64...)...)
+		checkCopied(t, dst.Writer, false, g.amd64...)
+	})
+
+	t.Run("referrers", func(t *testing.T) {
+		dst := newDestination(t)
+		if err := Copy(ctx, dst, g.src, g.index, Options{Referrers: true}); err != nil {
+			t.Fatal(err)
+		}
+		checkCopied(t, dst.Writer, true, g.signature...)
+
+		// the source must list referrers
+		src := struct{ content.Fetcher }{g.src}
+		if err := Copy(ctx, newDestination(t), src, g.index, Options{Referrers: true}); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("referrer listing its subject", func(t *testing.T) {
+		g := newTestGraph(t)
+		blob, err := json.Marshal(v1.Index{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: v1.MediaTypeImageIndex,
+			Manifests: []v1.Descriptor{g.amd64[0]},
+			Subject:   &g.amd64[0],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		referrer := push(t, g.src, v1.MediaTypeImageIndex, blob)
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		dst := newDestination(t)
+		if err := Copy(ctx, dst, g.src, g.index, Options{Referrers: true, Concurrency: 1}); err != nil {
+			t.Fatal(err)
+		}
+		checkCopied(t, dst.Writer, true, referrer)
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		dst := newDestination(t)
+		src := corruptFetcher{Fetcher: g.src, corrupt: g.arm64[2].Digest}
+		if err := Copy(ctx, dst, src, g.index, Options{Tag: "latest"}); err == nil {
+			t.Fatal("expected error")
+		}
+		checkCopied(t, dst.Writer, false, g.index, g.arm64[0], g.arm64[2])
+		if _, err := dst.Resolve(ctx, "latest"); err == nil {
+			t.Error("root tagged despite the error")
+		}
+	})
+
+	t.Run("concurrency", func(t *testing.T) {
+		for _, concurrency := range []int{1, 2} {
+			src := &recordingStore{Writer: g.src}
+			if err := Copy(ctx, newDestination(t), src, g.index, Options{Concurrency: concurrency}); err != nil {
+				t.Fatal(err)
+			}
+			if src.maxActive > concurrency {
+				t.Errorf("%d blobs fetched concurrently, expected at most %d", src.maxActive, concurrency)
+			}
+		}
+	})
+}